@@ -3,12 +3,13 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 
 	"cam/internal/ai"
+	"cam/internal/aicontext"
 	"cam/internal/data"
+	"cam/internal/prompts"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/spf13/cobra"
@@ -18,7 +19,12 @@ var askCmd = &cobra.Command{
 	Use:   "ask [question]",
 	Short: "Ask Gemini anything",
 	Long: `Ask your local Ollama model anything.
-Requires Ollama to be installed and running.`,
+Requires Ollama to be installed and running.
+
+Use -c to include context about the current directory. --ctx selects which
+context providers to use (tree, paths, git, readme, file, system, history,
+stacks), --file adds file contents and --tokens limits the context size.
+.gitignore and .camignore are respected.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		question := strings.Join(args, " ")
@@ -28,37 +34,33 @@ Requires Ollama to be installed and running.`,
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		ctx := context.Background()
+		ctx := aiContext(cmd, data.TaskAsk)
 
 		oneline, _ := cmd.Flags().GetBool("oneline")
 		withContext, _ := cmd.Flags().GetBool("context")
 
+		withContext = withContext || cmd.Flags().Changed("ctx") || cmd.Flags().Changed("file")
+
 		var contextStr string
 		if withContext {
-			contextStr = "directory tree:\n.\n" + buildFileTree(".", "", 0, 2)
-		}
-
-		var prompt strings.Builder
-		prompt.WriteString("SYSTEM: You are a concise CLI technical assistant.\n")
-		prompt.WriteString("RULES:\n")
-		prompt.WriteString("1. Keep answers short, accurate, and direct.\n")
-		prompt.WriteString("2. Avoid conversational filler (e.g. 'Here is a summary', 'I hope this helps').\n")
-		prompt.WriteString("3. Use markdown code blocks for examples.\n")
-		prompt.WriteString("\n")
-
-		if contextStr != "" {
-			prompt.WriteString(fmt.Sprintf("CONTEXT (Current Directory):\n%s\n", contextStr))
+			var err error
+			contextStr, err = buildContext(cmd, configStore, []string{"tree"})
+			if err != nil {
+				return err
+			}
 		}
 
+		vars := prompts.NewVars(question, contextStr)
 		if oneline {
-			prompt.WriteString("Provide a single-line plain text answer. No markdown. No explanations.\n")
-		} else {
-			prompt.WriteString("Provide a concise explanation using markdown.\n")
+			vars.Mode = "oneline"
 		}
 
-		prompt.WriteString(fmt.Sprintf("USER QUESTION: %s", question))
+		prompt, err := prompts.Render("ask", vars)
+		if err != nil {
+			return err
+		}
 
-		resultText, err := ai.GenerateContent(ctx, configStore, prompt.String())
+		resultText, err := ai.GenerateContent(ctx, configStore, prompt)
 		if err != nil {
 			return err
 		}
@@ -87,52 +89,48 @@ Requires Ollama to be installed and running.`,
 func init() {
 	askCmd.Flags().BoolP("oneline", "o", false, "Get a concise one-line answer")
 	askCmd.Flags().BoolP("context", "c", false, "Include local file context")
+	askCmd.Flags().Bool("no-cache", false, "Bypass the AI response cache")
+	contextFlags(askCmd)
 	rootCmd.AddCommand(askCmd)
 }
 
 // Shared with cmdr.go (package scope)
 
-func buildFileTree(dir string, prefix string, depth int, maxDepth int) string {
-	if depth > maxDepth {
-		return ""
-	}
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return ""
-	}
-
-	var validEntries []os.DirEntry
-	for _, e := range entries {
-		name := e.Name()
-		if strings.HasPrefix(name, ".") {
-			continue
-		}
-		if e.IsDir() && ignoredDirs[name] {
-			continue
-		}
-		validEntries = append(validEntries, e)
+// aiContext returns the context for AI calls to task's model, honouring --no-cache.
+func aiContext(cmd *cobra.Command, task string) context.Context {
+	ctx := ai.WithTask(context.Background(), task)
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache {
+		ctx = ai.WithoutCache(ctx)
 	}
+	return ctx
+}
 
-	var sb strings.Builder
-	for i, e := range validEntries {
-		isLast := i == len(validEntries)-1
-		connector := "├── "
-		newPrefix := prefix + "│   "
-		if isLast {
-			connector = "└── "
-			newPrefix = prefix + "    "
-		}
-
-		name := e.Name()
-		if e.IsDir() {
-			name += "/"
-		}
+// contextFlags registers the flags that control AI context gathering.
+func contextFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("ctx", nil, "Context providers to include (e.g. paths,git,readme,system,history,stacks)")
+	cmd.Flags().StringSlice("file", nil, "Include the contents of a file as context (repeatable)")
+	cmd.Flags().Int("tokens", 0, "Token budget for context (default from config)")
+}
 
-		sb.WriteString(fmt.Sprintf("%s%s%s\n", prefix, connector, name))
+// buildContext gathers AI context using the --ctx/--file/--tokens flags,
+// falling back to configured providers and then to defaults.
+func buildContext(cmd *cobra.Command, configStore *data.ConfigStore, defaults []string) (string, error) {
+	names, _ := cmd.Flags().GetStringSlice("ctx")
+	files, _ := cmd.Flags().GetStringSlice("file")
+	budget, _ := cmd.Flags().GetInt("tokens")
 
-		if e.IsDir() {
-			sb.WriteString(buildFileTree(filepath.Join(dir, e.Name()), newPrefix, depth+1, maxDepth))
-		}
+	if len(names) == 0 {
+		names = configStore.GetContextProviders()
+	}
+	if len(names) == 0 {
+		names = defaults
 	}
-	return sb.String()
+	if len(files) > 0 && !slices.Contains(names, "file") {
+		names = append(names, "file")
+	}
+	if budget <= 0 {
+		budget = configStore.GetContextTokens()
+	}
+
+	return aicontext.Build(names, aicontext.Options{Dir: ".", Files: files}, budget)
 }
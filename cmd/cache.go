@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"cam/internal/ai"
+	"cam/internal/data"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the AI response cache",
+	Long: `Identical ask/cmdr/fix prompts (same model, same context) are answered from
+an on-disk cache instead of hitting the model again.
+
+Configure it with:
+  cam config cache-ttl 24h   # how long answers are reused ("0" disables caching)
+  cam config cache-size 50   # size limit in MB
+
+Use --no-cache on ask, cmdr or fix to bypass it for a single call.`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show response cache statistics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configStore := data.NewConfigStore()
+		if err := configStore.LoadConfig(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		stats, err := ai.Stats(configStore)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Location: %s\n", stats.Dir)
+		fmt.Printf("Entries:  %d (%d expired)\n", stats.Entries, stats.Expired)
+		fmt.Printf("Size:     %.1f KB of %d MB\n", float64(stats.Bytes)/1024, configStore.GetCacheMaxBytes()/(1024*1024))
+		if ttl := configStore.GetCacheTTL(); ttl > 0 {
+			fmt.Printf("TTL:      %s\n", ttl)
+		} else {
+			fmt.Println("TTL:      disabled")
+		}
+		if stats.Entries > 0 {
+			fmt.Printf("Oldest:   %s\n", stats.Oldest.Format(time.RFC3339))
+			fmt.Printf("Newest:   %s\n", stats.Newest.Format(time.RFC3339))
+		}
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			fmt.Printf("Hits:     %d/%d (%.0f%%)\n", stats.Hits, lookups, 100*float64(stats.Hits)/float64(lookups))
+		}
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := ai.ClearCache()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d cached responses\n", removed)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheStatsCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
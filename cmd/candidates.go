@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cam/internal/ai"
+	"cam/internal/data"
+	"cam/internal/prompts"
+	"cam/internal/safety"
+	"cam/internal/search"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var explanationStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245")) // Grey
+
+// listMarker matches numbering or bullets models like to prepend ("1. ", "2) ", "- ").
+var listMarker = regexp.MustCompile(`^(\d+[.)]|[-*•])\s+`)
+
+// candidate is one alternative command offered by cmdr -n.
+type candidate struct {
+	Command     string
+	Explanation string
+}
+
+// generateCandidates asks the model for count alternative commands and returns
+// the distinct ones in the order given.
+func generateCandidates(ctx context.Context, configStore *data.ConfigStore, question string, contextStr string, examples []search.Result, count int) ([]candidate, error) {
+	vars := prompts.NewVars(question, contextStr)
+	vars.Count = count
+	for _, ex := range examples {
+		vars.Examples = append(vars.Examples, prompts.Example{Command: ex.Command.Cmd, Description: ex.Command.Description})
+	}
+
+	prompt, err := prompts.Render("candidates", vars)
+	if err != nil {
+		return nil, err
+	}
+
+	resultText, err := ai.GenerateContent(ctx, configStore, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := parseCandidates(resultText)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("AI response contained no usable commands. Raw: %s", resultText)
+	}
+	if len(candidates) > count {
+		candidates = candidates[:count] // Models don't always stop at the number asked for.
+	}
+	return candidates, nil
+}
+
+// parseCandidates reads "<command> ## <explanation>" lines, dropping fences,
+// numbering, comments and duplicates.
+func parseCandidates(resultText string) []candidate {
+	var candidates []candidate
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(resultText, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "```") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = listMarker.ReplaceAllString(line, "")
+
+		command, explanation, _ := strings.Cut(line, "##")
+		command = strings.Trim(strings.TrimSpace(command), "`")
+		explanation = strings.TrimSpace(explanation)
+		if command == "" {
+			continue
+		}
+
+		key := strings.Join(strings.Fields(command), " ")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		candidates = append(candidates, candidate{Command: command, Explanation: explanation})
+	}
+	return candidates
+}
+
+// pickCandidate shows candidates as a numbered list and returns the chosen command.
+func pickCandidate(candidates []candidate) (commandResult, error) {
+	for i, c := range candidates {
+		fmt.Printf("%d) %s\n", i+1, c.Command)
+		if c.Explanation != "" {
+			fmt.Println("   " + explanationStyle.Render(c.Explanation))
+		}
+		var notes []string
+		for _, r := range safety.Analyze(c.Command) {
+			notes = append(notes, r.Reason)
+		}
+		if missing := missingExecutables(c.Command); len(missing) > 0 {
+			notes = append(notes, "not installed: "+strings.Join(missing, ", "))
+		}
+		for _, note := range notes {
+			fmt.Println("   " + warningStyle.Render("⚠ "+note))
+		}
+	}
+
+	choice, err := pickOne(len(candidates))
+	if err != nil {
+		return commandResult{}, err
+	}
+	return commandResult{Command: candidates[choice].Command, Explanation: candidates[choice].Explanation}, nil
+}
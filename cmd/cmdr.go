@@ -2,13 +2,17 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"cam/internal/ai"
 	"cam/internal/data"
+	"cam/internal/prompts"
+	"cam/internal/safety"
+	"cam/internal/search"
+	"cam/internal/sysinfo"
 
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/lipgloss"
@@ -26,6 +30,19 @@ var (
 	successStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("86")). // Cyan/Greenish
 			Bold(true)
+
+	warningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")). // Orange
+			Bold(true)
+)
+
+const (
+	// maxExamples is how many saved commands are included in the prompt.
+	maxExamples = 5
+	// minExampleScore is the similarity below which saved commands aren't worth including.
+	minExampleScore = 0.4
+	// savedMatchScore is the similarity at which a saved command is offered instead of generating.
+	savedMatchScore = 0.85
 )
 
 var cmdrCmd = &cobra.Command{
@@ -33,7 +50,32 @@ var cmdrCmd = &cobra.Command{
 	Short: "Generate a shell command from a question",
 	Long: `Ask your local Ollama model to generate a specific shell command based on your request.
 Returns ONLY the command string, ready to copy-paste or pipe.
-Requires Ollama to be installed and running.`,
+Requires Ollama to be installed and running.
+
+When run in a terminal, an action prompt is shown after generation so the
+command can be run, pinned to a stack, edited, regenerated or copied.
+Use --pin <stack> to save the result directly without prompting.
+
+Context defaults to a flat file list of the current directory; use --ctx,
+--file and --tokens to choose providers, add files and limit the size.
+
+Your saved stacks are searched for commands relevant to the request and the
+best ones are included as examples, so generated commands follow your own
+conventions. If a saved command already matches with high confidence, it is
+offered directly instead. Only public commands are used unless -p is given.
+Use --no-examples to skip this.
+
+Use -n <count> to request several alternatives (different approaches or
+tools) and pick one from a list with short explanations. Without a terminal
+they are printed one per line instead.
+
+The prompt describes your shell, OS/distribution, coreutils flavour and
+installed tools. If the generated command uses executables that aren't on
+PATH, it is regenerated once and then flagged (skip with --no-validate).
+
+Generated commands are checked for destructive patterns and any findings are
+shown with the result. Running a risky command asks for confirmation unless
+--yes is given.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		question := strings.Join(args, " ")
@@ -43,157 +85,365 @@ Requires Ollama to be installed and running.`,
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		ctx := context.Background() // Context is always included for cmdr
+		ctx := aiContext(cmd, data.TaskCmdr) // Context is always included for cmdr
 
-		contextStr := "Directory structure (flat list):\n" + getFlatFileList(".", 3)
 		copyToClipboard, _ := cmd.Flags().GetBool("copy")
-
-		var prompt strings.Builder
-
-		prompt.WriteString("SYSTEM: You are a command-line interface expert. Your goal is to provide the exact shell command(s) the user needs.\n")
-		prompt.WriteString("OBJECTIVE: Convert the user's request (which might be a question or a statement) into a single valid shell command line.\n")
-		prompt.WriteString("RULES:\n")
-		prompt.WriteString("1. Output ONLY the command text. Do not include markdown formatting (like ```bash). Do not include explanations.\n")
-		prompt.WriteString("2. If multiple steps are required, chain them using '&&' or ';'.\n")
-		prompt.WriteString("3. If the user asks 'how to' or 'steps to', provide the actual commands to perform those steps.\n")
-		prompt.WriteString("4. Use the provided file list to resolve paths if applicable.\n")
-		prompt.WriteString("5. Assume a modern shell (bash/zsh).\n")
-		prompt.WriteString("\n")
-
-		if contextStr != "" {
-			prompt.WriteString(fmt.Sprintf("CONTEXT (File List):\n%s\n", contextStr))
-			prompt.WriteString("CRITICAL: Use the paths above to correct the user's request if needed.\n")
+		pinStack, _ := cmd.Flags().GetString("pin")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		includePrivate, _ := cmd.Flags().GetBool("private")
+		noExamples, _ := cmd.Flags().GetBool("no-examples")
+		noValidate, _ := cmd.Flags().GetBool("no-validate")
+		count, _ := cmd.Flags().GetInt("count")
+
+		contextStr, err := buildContext(cmd, configStore, []string{"paths"})
+		if err != nil {
+			return err
 		}
 
-		prompt.WriteString(fmt.Sprintf("USER REQUEST: %s\n", question))
-		prompt.WriteString("COMMAND:") // Pre-fill the start to encourage completion
+		var examples []search.Result
+		if !noExamples {
+			examples = retrieveSavedCommands(ctx, configStore, question, includePrivate)
+		}
+		if includePrivate {
+			ctx = ai.WithoutCache(ctx) // keep private examples out of the response cache
+		}
 
-		resultText, err := ai.GenerateContent(ctx, configStore, prompt.String())
-		if err != nil {
-			return err
+		generate := func(ctx context.Context) (commandResult, error) {
+			if noValidate {
+				return generateCommand(ctx, configStore, question, contextStr, examples, nil)
+			}
+			return generateAvailableCommand(ctx, configStore, question, contextStr, examples)
 		}
 
-		// Intelligent Parsing
-		// 1. If markdown blocks exist, take the content inside the first one
-		if start := strings.Index(resultText, "```"); start != -1 {
-			// Find end
-			end := strings.Index(resultText[start+3:], "```")
-			if end != -1 {
-				// content is between start+3 and start+3+end
-				codeBlock := resultText[start+3 : start+3+end]
-				// remove optional language identifier like "bash" or "sh"
-				lines := strings.Split(codeBlock, "\n")
-				if len(lines) > 0 {
-					firstLine := strings.TrimSpace(lines[0])
-					if firstLine == "bash" || firstLine == "sh" || firstLine == "zsh" {
-						codeBlock = strings.Join(lines[1:], "\n")
+		if count > 1 {
+			if !isInteractive() {
+				if pinStack != "" {
+					return fmt.Errorf("--pin with -n needs a terminal to pick a candidate")
+				}
+				candidates, err := generateCandidates(ctx, configStore, question, contextStr, examples, count)
+				if err != nil {
+					return err
+				}
+				for _, c := range candidates {
+					if c.Explanation == "" {
+						fmt.Println(c.Command)
+						continue
 					}
+					fmt.Printf("%s  # %s\n", c.Command, c.Explanation)
+				}
+				return nil
+			}
+
+			generate = func(ctx context.Context) (commandResult, error) {
+				candidates, err := generateCandidates(ctx, configStore, question, contextStr, examples, count)
+				if err != nil {
+					return commandResult{}, err
 				}
-				resultText = codeBlock
+				return pickCandidate(candidates)
 			}
 		}
 
-		// Intelligent Cleanup (Pre-Processing)
-		resultText = strings.TrimSpace(resultText)
+		if len(examples) > 0 && examples[0].Score >= savedMatchScore && pinStack == "" && isInteractive() {
+			best := examples[0]
+			fmt.Printf("Saved command matches your request: [%s] [%d]\n", best.Stack, best.Index)
+			saved := commandResult{Command: best.Command.Cmd, Explanation: best.Command.Description, IsPrivate: best.Command.IsPrivate}
+			showCommand(saved)
+			if confirm("Use it instead of generating a new one?", true) {
+				if copyToClipboard {
+					copyCommand(saved.Command)
+					return nil
+				}
+				return commandActions(ctx, saved, generate, assumeYes)
+			}
+		}
 
-		// 2. Intelligent Cleanup: Remove standalone language identifiers or comments if they appear at the start
-		lines := strings.Split(resultText, "\n")
+		result, err := generate(ctx)
+		if errors.Is(err, errCancelled) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
-		// Helper to check if a line is a language tag
-		isLanguageTag := func(s string) bool {
-			s = strings.TrimSpace(strings.ToLower(s))
-			switch s {
-			case "bash", "sh", "zsh", "shell", "console":
-				return true
-			}
-			return false
+		if copyToClipboard {
+			copyCommand(result.Command)
 		}
 
-		// Strip leading lines that are just language tags or comments
-		for len(lines) > 0 {
-			first := strings.TrimSpace(lines[0])
-			if isLanguageTag(first) || strings.HasPrefix(first, "#") {
-				lines = lines[1:] // drop the line
-				continue
+		showCommand(result)
+
+		if pinStack != "" {
+			if err := pinToStack(pinStack, result.Command, result.Explanation, nil, result.IsPrivate); err != nil {
+				return err
 			}
-			break
+			fmt.Println(successStyle.Render(fmt.Sprintf("✔ Pinned to '%s'", pinStack)))
+			return nil
 		}
 
-		if len(lines) == 0 {
-			return fmt.Errorf("AI response contained only filtered lines (comments/tags). Raw: %s", resultText)
+		if copyToClipboard || !isInteractive() {
+			return nil
 		}
 
-		resultText = strings.Join(lines, "\n")
-		resultText = strings.TrimSpace(resultText)
+		return commandActions(ctx, result, generate, assumeYes)
+	},
+}
 
-		// 2. If no markdown, but multiple lines, try to find the one that looks like a command?
-		// For now, prompt engineering should fix this, but let's just trim.
+// retrieveSavedCommands returns the stored commands most relevant to question,
+// best first. Retrieval is best-effort: without embeddings it falls back to
+// fuzzy matching, and any failure simply yields no examples. Private commands
+// are only ever fuzzy matched, so they never end up in the embedding cache.
+func retrieveSavedCommands(ctx context.Context, configStore *data.ConfigStore, question string, includePrivate bool) []search.Result {
+	// Private commands are always loaded and filtered here, so indexes match
+	// the positions 'cam run' uses.
+	store := data.NewDataStore()
+	if err := store.LoadData(true); err != nil {
+		return nil
+	}
 
-		if resultText == "" {
-			return fmt.Errorf("empty response from AI")
+	var items, privateItems []search.Item
+	for _, it := range search.Items(store.Stacks) {
+		switch {
+		case it.Command.Cmd == "":
+		case !it.Command.IsPrivate:
+			items = append(items, it)
+		case includePrivate:
+			privateItems = append(privateItems, it)
 		}
+	}
 
-		if copyToClipboard {
-			if err := clipboard.WriteAll(resultText); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to copy to clipboard: %v\n", err)
-			} else {
-				fmt.Println(successStyle.Render("✔ Copied to clipboard!"))
+	var relevant []search.Result
+	if len(items) > 0 {
+		results, err := search.Semantic(ctx, configStore, items, question)
+		if err != nil {
+			results = fuzzyExamples(items, question)
+		}
+		for _, r := range results {
+			if len(relevant) == maxExamples {
+				break
 			}
+			if r.Score >= minExampleScore || err != nil {
+				relevant = append(relevant, r)
+			}
+		}
+	}
+	for _, r := range fuzzyExamples(privateItems, question) {
+		if len(relevant) == maxExamples {
+			break
 		}
+		relevant = append(relevant, r)
+	}
+	return relevant
+}
 
-		fmt.Println(commandStyle.Render(resultText))
-		return nil
-	},
+// fuzzyExamples fuzzy matches items against question. Fuzzy scores aren't
+// comparable to similarities, so they are zeroed to never count as a direct match.
+func fuzzyExamples(items []search.Item, question string) []search.Result {
+	results := search.Fuzzy(items, question)
+	for i := range results {
+		results[i].Score = 0
+	}
+	return results
 }
 
-var ignoredDirs = map[string]bool{
-	"node_modules": true,
-	"vendor":       true,
-	"dist":         true,
-	"build":        true,
-	".git":         true,
-	".tea":         true,
-	"__pycache__":  true,
+// generateAvailableCommand generates a command and, if it uses executables that
+// aren't on PATH, regenerates once telling the model to avoid them. Anything
+// still missing afterwards is reported as a warning.
+func generateAvailableCommand(ctx context.Context, configStore *data.ConfigStore, question string, contextStr string, examples []search.Result) (commandResult, error) {
+	result, err := generateCommand(ctx, configStore, question, contextStr, examples, nil)
+	if err != nil {
+		return commandResult{}, err
+	}
+
+	missing := result.missing()
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Not installed: %s; regenerating...\n", strings.Join(missing, ", "))
+	if retry, err := generateCommand(ctx, configStore, question, contextStr, examples, missing); err == nil {
+		result = retry
+		missing = result.missing()
+	}
+
+	if len(missing) > 0 {
+		fmt.Fprintln(os.Stderr, warningStyle.Render("⚠ not found on PATH: "+strings.Join(missing, ", ")))
+	}
+	return result, nil
+}
+
+// missingExecutables lists the programs command runs that aren't installed.
+func missingExecutables(command string) []string {
+	names, err := safety.Executables(command)
+	if err != nil {
+		return nil // unparsable commands are reported by the safety check
+	}
+	return sysinfo.Missing(names)
 }
 
-func getFlatFileList(root string, maxDepth int) string {
-	var sb strings.Builder
+// generateCommand asks the model for a single shell command answering question.
+// unavailable lists executables the model must avoid.
+func generateCommand(ctx context.Context, configStore *data.ConfigStore, question string, contextStr string, examples []search.Result, unavailable []string) (commandResult, error) {
+	vars := prompts.NewVars(question, contextStr)
+	vars.Unavailable = unavailable
+	for _, ex := range examples {
+		vars.Examples = append(vars.Examples, prompts.Example{Command: ex.Command.Cmd, Description: ex.Command.Description})
+	}
+
+	return generateStructured(ctx, configStore, "cmdr", vars)
+}
+
+// parseCommandResponse extracts the bare command from free-form model output.
+func parseCommandResponse(resultText string) (string, error) {
+	// Intelligent Parsing
+	// 1. If markdown blocks exist, take the content inside the first one
+	if start := strings.Index(resultText, "```"); start != -1 {
+		// Find end
+		end := strings.Index(resultText[start+3:], "```")
+		if end != -1 {
+			// content is between start+3 and start+3+end
+			codeBlock := resultText[start+3 : start+3+end]
+			// remove optional language identifier like "bash" or "sh"
+			lines := strings.Split(codeBlock, "\n")
+			if len(lines) > 0 {
+				firstLine := strings.TrimSpace(lines[0])
+				if firstLine == "bash" || firstLine == "sh" || firstLine == "zsh" {
+					codeBlock = strings.Join(lines[1:], "\n")
+				}
+			}
+			resultText = codeBlock
+		}
+	}
+
+	// Intelligent Cleanup (Pre-Processing)
+	resultText = strings.TrimSpace(resultText)
+
+	// 2. Intelligent Cleanup: Remove standalone language identifiers or comments if they appear at the start
+	lines := strings.Split(resultText, "\n")
+
+	// Helper to check if a line is a language tag
+	isLanguageTag := func(s string) bool {
+		s = strings.TrimSpace(strings.ToLower(s))
+		switch s {
+		case "bash", "sh", "zsh", "shell", "console":
+			return true
+		}
+		return false
+	}
 
-	// Helper for recursion
-	var walk func(path string, depth int)
-	walk = func(path string, depth int) {
-		if depth > maxDepth {
-			return
+	// Strip leading lines that are just language tags or comments
+	for len(lines) > 0 {
+		first := strings.TrimSpace(lines[0])
+		if isLanguageTag(first) || strings.HasPrefix(first, "#") {
+			lines = lines[1:] // drop the line
+			continue
 		}
+		break
+	}
+
+	if len(lines) == 0 {
+		return "", fmt.Errorf("AI response contained only filtered lines (comments/tags). Raw: %s", resultText)
+	}
 
-		entries, err := os.ReadDir(path)
+	resultText = strings.Join(lines, "\n")
+	resultText = strings.TrimSpace(resultText)
+
+	// 2. If no markdown, but multiple lines, try to find the one that looks like a command?
+	// For now, prompt engineering should fix this, but let's just trim.
+
+	if resultText == "" {
+		return "", fmt.Errorf("empty response from AI")
+	}
+
+	return resultText, nil
+}
+
+// commandActions prompts the user for what to do with a generated command
+// until it is run, pinned, copied or the prompt is dismissed. Regenerating
+// always bypasses the response cache.
+func commandActions(ctx context.Context, result commandResult, regenerate func(context.Context) (commandResult, error), assumeYes bool) error {
+	for {
+		command := result.Command
+		choice, err := promptLine("[r]un  [p]in  [e]dit  [g]enerate again  [c]opy  [q]uit: ")
 		if err != nil {
-			return
+			return nil
 		}
 
-		for _, e := range entries {
-			name := e.Name()
-			if strings.HasPrefix(name, ".") {
-				continue // skip hidden
-			}
-			if e.IsDir() && ignoredDirs[name] {
+		switch strings.ToLower(choice) {
+		case "r", "run":
+			if ok, err := confirmSafe(command, assumeYes); !ok {
+				if err != nil {
+					return err
+				}
 				continue
 			}
+			fmt.Printf("Running: %s\n", command)
+			return execShell(command)
 
-			fullPath := filepath.Join(path, name)
-			sb.WriteString(fullPath + "\n")
+		case "p", "pin":
+			stackName, err := promptLine("Stack: ")
+			if err != nil || stackName == "" {
+				continue
+			}
+			if err := pinToStack(stackName, command, result.Explanation, nil, result.IsPrivate); err != nil {
+				return err
+			}
+			fmt.Println(successStyle.Render(fmt.Sprintf("✔ Pinned to '%s'", stackName)))
+			return nil
+
+		case "e", "edit":
+			fmt.Printf("Current: %s\n", command)
+			edited, err := promptLine("New command (enter to keep): ")
+			if err != nil {
+				return nil
+			}
+			if edited != "" && edited != command {
+				// The model's notes no longer describe the edited command.
+				result = commandResult{Command: edited, IsPrivate: result.IsPrivate}
+			}
+			showCommand(result)
 
-			if e.IsDir() {
-				walk(fullPath, depth+1)
+		case "g", "generate", "regenerate":
+			regenerated, err := regenerate(ai.WithoutCache(ctx))
+			if errors.Is(err, errCancelled) {
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to regenerate: %v\n", err)
+				continue
 			}
+			result = regenerated
+			showCommand(result)
+
+		case "c", "copy":
+			copyCommand(command)
+			return nil
+
+		case "", "q", "quit", "cancel":
+			return nil
+
+		default:
+			fmt.Printf("Unknown action '%s'\n", choice)
 		}
 	}
+}
 
-	walk(root, 1)
-	return sb.String()
+// copyCommand copies command to the clipboard, warning instead of failing.
+func copyCommand(command string) {
+	if err := clipboard.WriteAll(command); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to copy to clipboard: %v\n", err)
+	} else {
+		fmt.Println(successStyle.Render("✔ Copied to clipboard!"))
+	}
 }
 
 func init() {
 	cmdrCmd.Flags().BoolP("copy", "c", false, "Copy generated command to clipboard")
+	cmdrCmd.Flags().String("pin", "", "Pin generated command to the given stack")
+	cmdrCmd.Flags().BoolP("yes", "y", false, "Run risky commands without confirmation")
+	cmdrCmd.Flags().BoolP("private", "p", false, "Also use private commands as examples")
+	cmdrCmd.Flags().Bool("no-examples", false, "Don't include saved commands in the prompt")
+	cmdrCmd.Flags().Bool("no-cache", false, "Bypass the AI response cache")
+	cmdrCmd.Flags().IntP("count", "n", 1, "Generate several alternative commands to pick from")
+	cmdrCmd.Flags().Bool("no-validate", false, "Don't check that the command's executables are installed")
+	contextFlags(cmdrCmd)
 	rootCmd.AddCommand(cmdrCmd)
 }
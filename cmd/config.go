@@ -2,53 +2,205 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
+	"cam/internal/aicontext"
 	"cam/internal/data"
 
 	"github.com/spf13/cobra"
 )
 
 var configCmd = &cobra.Command{
-	Use:   "config <key> <value>",
-	Short: "Set configuration values",
-	Long: `Set configuration values for cam.
-Supported keys:
-  - model: Set the Ollama model (e.g. "qwen2.5", "llama3").
-    Find models at: https://ollama.com/library`,
+	Use:   "config [key] [value]",
+	Short: "Get and set configuration values",
+	Long: `Get and set configuration values for cam.
+
+  cam config list                 # every key with its value and source
+  cam config get <key>
+  cam config set <key> <value>    # also: cam config <key> <value>
+  cam config unset <key>          # restore the default (or: set <key> default)
+  cam config edit                 # open config.json in $EDITOR
+
+Every key can be overridden with an environment variable named after it,
+e.g. CAM_MODEL or CAM_CACHE_TTL. Use --config to read another config file.
+
+Models must be installed locally (see 'cam models ls'); --force skips the check.
+Find models at: https://ollama.com/library`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		key := args[0]
-		var value string
-		if len(args) > 1 {
-			value = args[1]
+		if len(args) == 1 {
+			return configGetCmd.RunE(cmd, args)
 		}
+		return configSetCmd.RunE(cmd, args)
+	},
+}
 
-		store := data.NewConfigStore()
-		if err := store.LoadConfig(); err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadConfigStore()
+		if err != nil {
+			return err
+		}
+
+		value, source, err := store.Get(strings.ToLower(args[0]))
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			value = "(unset)"
+		}
+		fmt.Printf("%s (%s)\n", value, source)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Save a value to the config file",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := strings.ToLower(args[0]), args[1]
+		force, _ := cmd.Flags().GetBool("force")
+
+		if value == "default" {
+			return configUnsetCmd.RunE(cmd, args[:1])
+		}
+
+		store, err := loadConfigStore()
+		if err != nil {
+			return err
 		}
 
-		switch strings.ToLower(key) {
-		case "model":
-			if value == "" || value == "-h" || value == "--help" {
-				fmt.Printf("Current model: %s\n", store.GetOllamaModel())
-				fmt.Println("Find models to use at: https://ollama.com/library")
-				return nil
+		if _, ok := data.LookupKey(key); !ok {
+			return fmt.Errorf("unknown configuration key: '%s' (see 'cam config list')", key)
+		}
+		if check, ok := configChecks[key]; ok && !force {
+			if err := check(value); err != nil {
+				return err
 			}
-			if err := store.SetOllamaModel(value); err != nil {
-				return fmt.Errorf("failed to save model: %w", err)
+		}
+		if err := store.Set(key, value); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s set to: %s\n", key, store.String(key))
+		if k, _ := data.LookupKey(key); os.Getenv(k.EnvVar()) != "" {
+			fmt.Fprintf(os.Stderr, "Warning: %s is set and overrides this value\n", k.EnvVar())
+		}
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a value from the config file, restoring the default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := strings.ToLower(args[0])
+
+		store, err := loadConfigStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Unset(key); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s reset to default\n", key)
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every configuration key",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadConfigStore()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Config file: %s\n\n", store.Path())
+		for _, k := range data.Keys() {
+			value, source, _ := store.Get(k.Name)
+			if value == "" {
+				value = "(unset)"
 			}
-			fmt.Printf("Ollama model set to: %s\n", value)
+			fmt.Printf("%-15s %-24s %s\n", k.Name, value, explanationStyle.Render("("+source+")"))
+			fmt.Printf("%-15s %s\n", "", explanationStyle.Render(fmt.Sprintf("%s [%s, %s]", k.Description, k.Type, k.EnvVar())))
+		}
+		return nil
+	},
+}
 
-		default:
-			return fmt.Errorf("unknown configuration key: '%s'", key)
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in $EDITOR",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadConfigStore()
+		if err != nil {
+			return err
 		}
+		// Write the file first so the editor opens the current settings.
+		if err := store.SaveConfig(); err != nil {
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+
+		if err := openEditor(store.Path()); err != nil {
+			return err
+		}
+
+		if err := store.LoadConfig(); err != nil {
+			return fmt.Errorf("saved, but the config file is invalid (run 'cam config edit' again): %w", err)
+		}
+		for _, err := range store.Validate() {
+			fmt.Fprintf(os.Stderr, "Warning: %v; the default will be used\n", err)
+		}
+		fmt.Println(successStyle.Render("✔ Saved config"))
+		return nil
+	},
+}
 
+// configChecks are checks that need more than the registry knows, such as
+// whether a model is installed. They are skipped with --force.
+var configChecks = map[string]func(value string) error{
+	data.KeyModel:      checkModelInstalled,
+	data.KeyAskModel:   checkModelInstalled,
+	data.KeyCmdrModel:  checkModelInstalled,
+	data.KeyEmbedModel: checkModelInstalled,
+	data.KeyContext: func(value string) error {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if _, ok := aicontext.Lookup(name); !ok && name != "" {
+				var names []string
+				for _, p := range aicontext.Providers() {
+					names = append(names, p.Name)
+				}
+				return fmt.Errorf("unknown context provider '%s' (available: %s)", name, strings.Join(names, ", "))
+			}
+		}
 		return nil
 	},
 }
 
+// loadConfigStore returns the loaded config store.
+func loadConfigStore() (*data.ConfigStore, error) {
+	store := data.NewConfigStore()
+	if err := store.LoadConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return store, nil
+}
+
 func init() {
+	configCmd.Flags().Bool("force", false, "Save a model even if it isn't installed")
+	configSetCmd.Flags().Bool("force", false, "Save a model even if it isn't installed")
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd, configEditCmd)
 	rootCmd.AddCommand(configCmd)
 }
@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"cam/internal/ai"
+	"cam/internal/data"
+	"cam/internal/prompts"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// maxTags is how many suggested tags are kept per command.
+	maxTags = 4
+	// maxKnownTags is how many existing tags are offered to the model as a vocabulary.
+	maxKnownTags = 40
+)
+
+// enrichment is a suggested description and tags for a saved command.
+type enrichment struct {
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// enrichmentSchema constrains enrich answers to an enrichment.
+var enrichmentSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"description": map[string]any{"type": "string"},
+		"tags":        map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	},
+	"required": []string{"description", "tags"},
+}
+
+var enrichCmd = &cobra.Command{
+	Use:   "enrich <stack>",
+	Short: "Suggest descriptions and tags for saved commands",
+	Long: `Ask your local Ollama model to propose a one-line description and tags for
+each command in a stack that is missing them, and confirm each suggestion
+before it is saved. Descriptions and tags improve filtering and 'cam search'.
+
+Use --all to also revisit commands that already have both (replacing their
+description and tags once confirmed), -p to include
+private commands (their suggestions are never cached) and --yes to accept
+every suggestion without asking.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackName := args[0]
+		all, _ := cmd.Flags().GetBool("all")
+		includePrivate, _ := cmd.Flags().GetBool("private")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+
+		if !assumeYes && !isInteractive() {
+			return fmt.Errorf("suggestions need confirmation; run in a terminal or pass --yes")
+		}
+
+		configStore := data.NewConfigStore()
+		if err := configStore.LoadConfig(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		store := data.NewDataStore()
+		if err := store.LoadData(true); err != nil {
+			return fmt.Errorf("failed to load data store: %w", err)
+		}
+
+		stack := store.GetStack(stackName)
+		if len(stack) == 0 {
+			return fmt.Errorf("stack '%s' does not exist", stackName)
+		}
+		if store.IsReadOnly(stackName) {
+			return fmt.Errorf("stack '%s' is read-only", stackName)
+		}
+
+		ctx := aiContext(cmd, data.TaskCmdr)
+		knownTags := collectTags(store)
+
+		// Accepted suggestions by index, applied to freshly loaded stacks at the end.
+		accepted := make(map[int]enrichment)
+		for i, c := range stack {
+			if c.IsPrivate && !includePrivate {
+				continue
+			}
+			if c.Cmd == "" || (!all && c.Description != "" && len(c.Tags) > 0) {
+				continue
+			}
+
+			cmdCtx := ctx
+			if c.IsPrivate {
+				cmdCtx = ai.WithoutCache(ctx)
+			}
+			suggestion, err := suggestEnrichment(cmdCtx, configStore, stackName, c.Cmd, knownTags)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: no suggestion for '%s': %v\n", c.Cmd, err)
+				continue
+			}
+			mergeEnrichment(&suggestion, c, all)
+
+			ok := assumeYes
+			if !assumeYes {
+				ok, err = reviewEnrichment(c.Cmd, &suggestion)
+				if errors.Is(err, errCancelled) {
+					break
+				}
+			} else {
+				printEnrichment(c.Cmd, suggestion)
+			}
+			if !ok {
+				continue
+			}
+
+			accepted[i] = suggestion
+			knownTags = appendTags(knownTags, suggestion.Tags)
+		}
+
+		if len(accepted) == 0 {
+			fmt.Println("No commands updated.")
+			return nil
+		}
+
+		// The stack may have changed while reviewing, so commands are found again by their text.
+		updated := 0
+		fresh := data.NewDataStore()
+		err := fresh.Update(func() error {
+			current := fresh.GetStack(stackName)
+			for i, suggestion := range accepted {
+				j := findCommand(current, stack[i].Cmd, i)
+				if j < 0 {
+					continue
+				}
+				current[j].Description = suggestion.Description
+				current[j].Tags = suggestion.Tags
+				updated++
+			}
+			if updated > 0 {
+				fresh.Stacks[stackName] = current
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if skipped := len(accepted) - updated; skipped > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %d commands were changed or removed meanwhile and were not updated\n", skipped)
+		}
+
+		fmt.Println(successStyle.Render(fmt.Sprintf("✔ Updated %d commands in '%s'", updated, stackName)))
+		return nil
+	},
+}
+
+// findCommand returns the index of cmdStr in stack, preferring index when
+// several commands match, or -1 if it's gone.
+func findCommand(stack []data.Command, cmdStr string, index int) int {
+	if index < len(stack) && stack[index].Cmd == cmdStr {
+		return index
+	}
+	return slices.IndexFunc(stack, func(c data.Command) bool { return c.Cmd == cmdStr })
+}
+
+// suggestEnrichment asks the model for a description and tags for command,
+// using structured output when available and two labelled lines otherwise.
+func suggestEnrichment(ctx context.Context, configStore *data.ConfigStore, stackName string, command string, knownTags []string) (enrichment, error) {
+	vars := prompts.NewVars(command, "")
+	vars.Stack = stackName
+	vars.Tags = knownTags
+	vars.Format = "json"
+	prompt, err := prompts.Render("enrich", vars)
+	if err != nil {
+		return enrichment{}, err
+	}
+
+	resultText, err := ai.GenerateJSON(ctx, configStore, prompt, enrichmentSchema)
+	if err != nil && !errors.Is(err, ai.ErrStructuredUnsupported) {
+		return enrichment{}, err
+	}
+	if err == nil {
+		var e enrichment
+		if err := json.Unmarshal([]byte(resultText), &e); err == nil && strings.TrimSpace(e.Description) != "" {
+			return normalizeEnrichment(e), nil
+		}
+	}
+
+	vars.Format = ""
+	prompt, err = prompts.Render("enrich", vars)
+	if err != nil {
+		return enrichment{}, err
+	}
+
+	resultText, err = ai.GenerateContent(ctx, configStore, prompt)
+	if err != nil {
+		return enrichment{}, err
+	}
+
+	e := parseEnrichment(resultText)
+	if e.Description == "" && len(e.Tags) == 0 {
+		return enrichment{}, fmt.Errorf("AI response contained no description or tags. Raw: %s", resultText)
+	}
+	return e, nil
+}
+
+// parseEnrichment reads "DESCRIPTION:" and "TAGS:" lines from free-form output.
+func parseEnrichment(resultText string) enrichment {
+	var e enrichment
+	for _, line := range strings.Split(resultText, "\n") {
+		label, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(strings.Trim(label, "*# ")) {
+		case "DESCRIPTION":
+			e.Description = value
+		case "TAGS":
+			e.Tags = strings.Split(value, ",")
+		}
+	}
+	return normalizeEnrichment(e)
+}
+
+// normalizeEnrichment trims the description and turns tags into unique,
+// lowercase, hyphenated words.
+func normalizeEnrichment(e enrichment) enrichment {
+	e.Description = strings.Trim(strings.TrimSpace(e.Description), `"`)
+
+	var tags []string
+	for _, t := range e.Tags {
+		t = strings.Join(strings.Fields(strings.ToLower(strings.Trim(t, " #`\""))), "-")
+		if t == "" || len(tags) == maxTags || slices.Contains(tags, t) {
+			continue
+		}
+		tags = append(tags, t)
+	}
+	e.Tags = tags
+	return e
+}
+
+// mergeEnrichment keeps what the user already wrote: an existing description
+// wins and existing tags are kept ahead of new ones, unless overwrite is set.
+func mergeEnrichment(e *enrichment, c data.Command, overwrite bool) {
+	if overwrite {
+		return
+	}
+	if c.Description != "" {
+		e.Description = c.Description
+	}
+	e.Tags = appendTags(append([]string(nil), c.Tags...), e.Tags)
+}
+
+func printEnrichment(command string, e enrichment) {
+	fmt.Println(commandStyle.Render(command))
+	fmt.Printf("Description: %s\n", e.Description)
+	fmt.Printf("Tags:        %s\n", strings.Join(e.Tags, ", "))
+}
+
+// reviewEnrichment shows a suggestion and asks whether to keep it, letting the
+// user edit it first. It returns errCancelled when the user quits.
+func reviewEnrichment(command string, e *enrichment) (bool, error) {
+	printEnrichment(command, *e)
+	for {
+		choice, err := promptLine("[y]es  [n]o  [e]dit  [q]uit: ")
+		if err != nil {
+			return false, errCancelled
+		}
+
+		switch strings.ToLower(choice) {
+		case "y", "yes":
+			return true, nil
+		case "", "n", "no", "s", "skip":
+			return false, nil
+		case "q", "quit":
+			return false, errCancelled
+		case "e", "edit":
+			desc, err := promptLine("Description (enter to keep): ")
+			if err != nil {
+				return false, errCancelled
+			}
+			if desc != "" {
+				e.Description = desc
+			}
+			tags, err := promptLine("Tags, comma separated (enter to keep): ")
+			if err != nil {
+				return false, errCancelled
+			}
+			if tags != "" {
+				e.Tags = normalizeEnrichment(enrichment{Tags: strings.Split(tags, ",")}).Tags
+			}
+			return true, nil
+		default:
+			fmt.Printf("Unknown action '%s'\n", choice)
+		}
+	}
+}
+
+// collectTags returns the tags used across the store, most used first.
+func collectTags(store *data.DataStore) []string {
+	counts := make(map[string]int)
+	for _, commands := range store.Stacks {
+		for _, c := range commands {
+			for _, t := range c.Tags {
+				counts[t]++
+			}
+		}
+	}
+
+	var tags []string
+	for t := range counts {
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	if len(tags) > maxKnownTags {
+		tags = tags[:maxKnownTags]
+	}
+	return tags
+}
+
+// appendTags appends the tags not already in dst.
+func appendTags(dst []string, tags []string) []string {
+	for _, t := range tags {
+		if !slices.Contains(dst, t) {
+			dst = append(dst, t)
+		}
+	}
+	return dst
+}
+
+func init() {
+	enrichCmd.Flags().Bool("all", false, "also revisit commands that already have a description and tags")
+	enrichCmd.Flags().BoolP("private", "p", false, "include private commands")
+	enrichCmd.Flags().BoolP("yes", "y", false, "accept every suggestion without asking")
+	enrichCmd.Flags().Bool("no-cache", false, "Bypass the AI response cache")
+	rootCmd.AddCommand(enrichCmd)
+}
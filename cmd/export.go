@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cam/internal/bundle"
+	"cam/internal/crypto"
+	"cam/internal/data"
+	"cam/internal/interop"
+	"cam/internal/scriptgen"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [stack...]",
+	Short: "Export stacks as a bundle or for other snippet managers",
+	Long: `Export stacks (all personal stacks by default) to share them.
+
+By default this writes a cam bundle: a versioned JSON document (YAML with
+--format yaml or a .yaml/.yml output file) that 'cam import <file>' reads
+back. Private commands are left out unless --recipient gives the public key
+(from 'cam keys public') to encrypt them for. --sign signs the bundle with
+your signing key ('cam keys sign-init') so others can verify it came from
+you.
+
+Other snippet managers' formats are available with --format:
+
+  pet    pet snippets (TOML)
+  navi   navi cheatsheets (.cheat)
+  tldr   tldr-style markdown pages
+  warp   Warp workflows (YAML)
+
+Descriptions, tags and <name>/<name=default> placeholders are carried over;
+formats without stacks (pet, warp) get the stack as their first tag. Output
+goes to stdout, to the file given with -o, or, when -o is a directory, to one
+file per stack (per command for warp).
+
+--as turns stacks into something that runs without cam:
+
+  aliases    alias definitions to source (functions where an alias can't work)
+  functions  shell functions; placeholders become $1, $2, ...
+  script     an executable bash script running the stacks in order;
+             placeholders become environment variables
+  makefile   one target per command; placeholders become make variables
+  justfile   one recipe per command; placeholders become recipe
+             parameters, required ones first
+
+Names come from descriptions (or the command) and start with the stack name
+unless --no-prefix is given. Only bundles with --recipient include private
+commands.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formatName, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		recipient, _ := cmd.Flags().GetString("recipient")
+		as, _ := cmd.Flags().GetString("as")
+		noPrefix, _ := cmd.Flags().GetBool("no-prefix")
+		sign, _ := cmd.Flags().GetBool("sign")
+
+		if as != "" {
+			if formatName != "" || recipient != "" || sign {
+				return fmt.Errorf("--as can't be combined with --format, --recipient or --sign")
+			}
+			return exportGenerated(args, output, as, !noPrefix)
+		}
+
+		if formatName == "" {
+			formatName = "json"
+			if ext := filepath.Ext(output); ext == ".yaml" || ext == ".yml" {
+				formatName = "yaml"
+			}
+		}
+		if formatName == "json" || formatName == "yaml" {
+			return exportBundle(args, output, recipient, sign, formatName == "yaml")
+		}
+		if recipient != "" || sign {
+			return fmt.Errorf("--recipient and --sign only apply to bundles (json or yaml)")
+		}
+
+		format, err := interop.Lookup(formatName)
+		if err != nil {
+			return fmt.Errorf("unknown format '%s' (supported: json, yaml, %s)", formatName, strings.Join(interop.Names(), ", "))
+		}
+
+		store := data.NewDataStore()
+		if err := store.LoadData(false); err != nil {
+			return fmt.Errorf("failed to load data store: %w", err)
+		}
+
+		names, err := exportStacks(store, args)
+		if err != nil {
+			return err
+		}
+		snippets := exportSnippets(store, names)
+		if len(snippets) == 0 {
+			return fmt.Errorf("no public commands to export")
+		}
+
+		if output == "" {
+			return format.Write(os.Stdout, snippets)
+		}
+
+		if info, err := os.Stat(output); (err == nil && info.IsDir()) || strings.HasSuffix(output, string(os.PathSeparator)) {
+			files, err := format.WriteDir(output, snippets)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d commands to %d files in %s\n", len(snippets), len(files), output)
+			return nil
+		}
+
+		if err := writeFile(output, func(w io.Writer) error { return format.Write(w, snippets) }); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d commands to %s\n", len(snippets), output)
+		return nil
+	},
+}
+
+func exportBundle(stacks []string, output, recipient string, sign, asYAML bool) error {
+	b, err := bundle.New(recipient)
+	if err != nil {
+		return err
+	}
+
+	store := data.NewDataStore()
+	if err := store.LoadData(true); err != nil {
+		return fmt.Errorf("failed to load data store: %w", err)
+	}
+
+	var signingKey ed25519.PrivateKey
+	if sign {
+		keysDir, err := store.EnsureKeys()
+		if err != nil {
+			return err
+		}
+		if signingKey, err = crypto.LoadSigningKey(keysDir); err != nil {
+			return err
+		}
+	}
+
+	names, err := exportStacks(store, stacks)
+	if err != nil {
+		return err
+	}
+
+	left := 0
+	for _, name := range names {
+		skipped, err := b.AddStack(strings.TrimPrefix(name, data.ProjectPrefix), store.Stacks[name])
+		if err != nil {
+			return err
+		}
+		left += skipped
+	}
+	public, private := b.Count()
+	if public+private == 0 {
+		return fmt.Errorf("no commands to export")
+	}
+	if signingKey != nil {
+		if err := b.Sign(signingKey); err != nil {
+			return err
+		}
+	}
+
+	if output == "" {
+		if err := b.Encode(os.Stdout, asYAML); err != nil {
+			return err
+		}
+	} else if err := writeFile(output, func(w io.Writer) error { return b.Encode(w, asYAML) }); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Exported %d commands from %d stacks", public+private, len(b.Stacks))
+	if output != "" {
+		msg += " to " + output
+	}
+	if private > 0 {
+		msg += fmt.Sprintf(" (%d private, encrypted for %s)", private, b.Recipient)
+	}
+	if b.Signature != nil {
+		msg += ", signed " + crypto.SigningFingerprint(b.Signature.Signer)
+	}
+	fmt.Fprintln(os.Stderr, msg)
+	if left > 0 {
+		fmt.Fprintf(os.Stderr, "Left out %d private commands; use --recipient <public key> to include them.\n", left)
+	}
+	return nil
+}
+
+func exportGenerated(stacks []string, output, as string, prefix bool) error {
+	if as == scriptgen.Script && len(stacks) == 0 {
+		return fmt.Errorf("name the stacks to put in the script")
+	}
+
+	store := data.NewDataStore()
+	if err := store.LoadData(false); err != nil {
+		return fmt.Errorf("failed to load data store: %w", err)
+	}
+	names, err := exportStacks(store, stacks)
+	if err != nil {
+		return err
+	}
+	snippets := exportSnippets(store, names)
+	if len(snippets) == 0 {
+		return fmt.Errorf("no public commands to export")
+	}
+
+	if output == "" {
+		return scriptgen.Generate(os.Stdout, as, snippets, prefix)
+	}
+	if err := writeFile(output, func(w io.Writer) error { return scriptgen.Generate(w, as, snippets, prefix) }); err != nil {
+		return err
+	}
+	if as == scriptgen.Script {
+		if err := os.Chmod(output, 0755); err != nil {
+			return fmt.Errorf("failed to make %s executable: %w", output, err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d commands to %s\n", len(snippets), output)
+	return nil
+}
+
+// exportSnippets converts the public commands of stacks, in stack order.
+func exportSnippets(store *data.DataStore, stacks []string) []interop.Snippet {
+	var snippets []interop.Snippet
+	for _, name := range stacks {
+		for _, c := range store.Stacks[name] {
+			if c.IsPrivate {
+				continue
+			}
+			snippets = append(snippets, interop.Snippet{
+				Stack:       strings.TrimPrefix(name, data.ProjectPrefix),
+				Command:     c.Cmd,
+				Description: c.Description,
+				Tags:        c.Tags,
+			})
+		}
+	}
+	return snippets
+}
+
+// exportStacks resolves the stacks to export: the named ones, or every
+// personal stack (project stacks are already shared through the repository).
+func exportStacks(store *data.DataStore, stacks []string) ([]string, error) {
+	if len(stacks) == 0 {
+		for name := range store.Stacks {
+			if !store.IsReadOnly(name) {
+				stacks = append(stacks, name)
+			}
+		}
+		sort.Strings(stacks)
+		return stacks, nil
+	}
+
+	for _, name := range stacks {
+		if _, ok := store.Stacks[name]; !ok {
+			return nil, fmt.Errorf("stack '%s' not found", name)
+		}
+	}
+	return stacks, nil
+}
+
+// writeFile creates path and fills it with write, reporting close errors.
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", "", "output format: json (default), yaml, "+strings.Join(interop.Names(), ", "))
+	exportCmd.Flags().StringP("output", "o", "", "file or directory to write instead of stdout")
+	exportCmd.Flags().String("recipient", "", "public key to encrypt private commands for (bundles only)")
+	exportCmd.Flags().Bool("sign", false, "sign the bundle with your signing key")
+	exportCmd.Flags().String("as", "", "generate "+strings.Join(scriptgen.Kinds, ", ")+" instead")
+	exportCmd.Flags().Bool("no-prefix", false, "don't start generated names with the stack name")
+	rootCmd.AddCommand(exportCmd)
+}
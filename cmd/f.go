@@ -5,29 +5,11 @@ import (
 	"strings"
 
 	"cam/internal/data"
+	"cam/internal/search"
 
-	"github.com/sahilm/fuzzy"
 	"github.com/spf13/cobra"
 )
 
-type commandSource struct {
-	items []commandItem
-}
-
-type commandItem struct {
-	Stack string
-	Index int
-	Cmd   string
-}
-
-func (s *commandSource) String(i int) string {
-	return s.items[i].Cmd
-}
-
-func (s *commandSource) Len() int {
-	return len(s.items)
-}
-
 var fCmd = &cobra.Command{
 	Use:   "f [query]",
 	Short: "Fuzzy search to find a command across all stacks",
@@ -42,18 +24,7 @@ Displays the stack name, index, and command for matches, sorted by relevance.`,
 			return fmt.Errorf("failed to load data: %w", err)
 		}
 
-		var source commandSource
-		for stackName, commands := range store.Stacks {
-			for i, c := range commands {
-				source.items = append(source.items, commandItem{
-					Stack: stackName,
-					Index: i,
-					Cmd:   c.Cmd,
-				})
-			}
-		}
-
-		matches := fuzzy.FindFrom(query, &source)
+		matches := search.Fuzzy(search.Items(store.Stacks), query)
 
 		if len(matches) == 0 {
 			fmt.Printf("No matches found for '%s'\n", query)
@@ -61,8 +32,7 @@ Displays the stack name, index, and command for matches, sorted by relevance.`,
 		}
 
 		for _, match := range matches {
-			item := source.items[match.Index]
-			fmt.Printf("[%s] [%d] %s\n", item.Stack, item.Index, item.Cmd)
+			fmt.Printf("[%s] [%d] %s\n", match.Stack, match.Index, match.Command.Cmd)
 		}
 
 		return nil
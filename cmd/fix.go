@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cam/internal/data"
+	"cam/internal/history"
+	"cam/internal/prompts"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// maxPromptStderr bounds how much captured error output is sent to the model.
+const maxPromptStderr = 2000
+
+var (
+	removedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Strikethrough(true)
+	addedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("86")).Bold(true)
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix [command]",
+	Short: "Repair the last failed command with AI",
+	Long: `Send the last failed command, its exit code and (when available) its error
+output to your local Ollama model and propose a corrected command.
+
+The failed command is read from the shell integration (see 'cam shell-init').
+Error output is only available for commands run through 'cam wrap'.
+Without shell integration, the last entry of your shell history is used, or
+you can pass the failed command explicitly.
+
+The correction is shown as a diff, followed by the same run/pin/edit/copy
+actions as 'cam cmdr'.
+
+Example:
+  cam fix
+  cam fix -- git comit -m "wip"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configStore := data.NewConfigStore()
+		if err := configStore.LoadConfig(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		rec, err := lastFailedCommand(args)
+		if err != nil {
+			return err
+		}
+
+		ctx := aiContext(cmd, data.TaskCmdr)
+		copyToClipboard, _ := cmd.Flags().GetBool("copy")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+
+		contextStr, err := buildContext(cmd, configStore, []string{"paths"})
+		if err != nil {
+			return err
+		}
+
+		generate := func(ctx context.Context) (commandResult, error) {
+			return generateFix(ctx, configStore, rec, contextStr)
+		}
+
+		fixed, err := generate(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(renderCommandDiff(rec.Command, fixed.Command))
+		printNotes(fixed)
+
+		if copyToClipboard {
+			copyCommand(fixed.Command)
+			return nil
+		}
+
+		if !isInteractive() {
+			return nil
+		}
+
+		return commandActions(ctx, fixed, generate, assumeYes)
+	},
+}
+
+// lastFailedCommand resolves the command to repair from args, the shell
+// integration record, or the shell history, in that order.
+func lastFailedCommand(args []string) (*history.Record, error) {
+	if len(args) > 0 {
+		return &history.Record{Command: strings.Join(args, " "), ExitCode: -1}, nil
+	}
+
+	if dir, err := data.DataRoot(); err == nil {
+		if rec, err := history.ReadRecord(dir); err == nil {
+			if rec.ExitCode == 0 {
+				return nil, fmt.Errorf("last command succeeded: %s\npass the command to fix explicitly: cam fix <command>", rec.Command)
+			}
+			return rec, nil
+		}
+	}
+
+	entries, err := history.Load(history.DetectShell())
+	if err != nil {
+		return nil, fmt.Errorf("no shell integration record found (see 'cam shell-init') and %w", err)
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(entries[i].Command, "cam fix") {
+			return &history.Record{Command: entries[i].Command, ExitCode: -1}, nil
+		}
+	}
+	return nil, fmt.Errorf("no previous command found; pass the command to fix explicitly: cam fix <command>")
+}
+
+// generateFix asks the model to correct a failed command.
+func generateFix(ctx context.Context, configStore *data.ConfigStore, rec *history.Record, contextStr string) (commandResult, error) {
+	stderr := strings.TrimSpace(rec.Stderr)
+	if len(stderr) > maxPromptStderr {
+		stderr = stderr[len(stderr)-maxPromptStderr:]
+	}
+
+	vars := prompts.NewVars("", contextStr)
+	vars.Failure = prompts.Failure{Command: rec.Command, ExitCode: rec.ExitCode, Stderr: stderr}
+
+	return generateStructured(ctx, configStore, "fix", vars)
+}
+
+// renderCommandDiff shows a word-level diff between the original and fixed command.
+func renderCommandDiff(original, fixed string) string {
+	a := strings.Fields(original)
+	b := strings.Fields(fixed)
+
+	// Longest common subsequence table over words.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var removed, added []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			removed = append(removed, a[i])
+			added = append(added, b[j])
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			added = append(added, addedStyle.Render(b[j]))
+			j++
+		default:
+			removed = append(removed, removedStyle.Render(a[i]))
+			i++
+		}
+	}
+
+	return fmt.Sprintf("- %s\n+ %s", strings.Join(removed, " "), strings.Join(added, " "))
+}
+
+func init() {
+	fixCmd.Flags().BoolP("copy", "c", false, "Copy the corrected command to clipboard")
+	fixCmd.Flags().BoolP("yes", "y", false, "Run risky commands without confirmation")
+	fixCmd.Flags().Bool("no-cache", false, "Bypass the AI response cache")
+	contextFlags(fixCmd)
+	rootCmd.AddCommand(fixCmd)
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"cam/internal/ai"
+	"cam/internal/data"
+	"cam/internal/prompts"
+	"cam/internal/safety"
+
+	"github.com/spf13/cobra"
+)
+
+// step is one command of a generated stack, in run order.
+type step struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// stepsSchema constrains gen-stack answers to an ordered list of steps.
+var stepsSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"steps": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"command":     map[string]any{"type": "string"},
+					"description": map[string]any{"type": "string"},
+				},
+				"required": []string{"command", "description"},
+			},
+		},
+	},
+	"required": []string{"steps"},
+}
+
+var genStackCmd = &cobra.Command{
+	Use:   "gen-stack <stack> <goal>",
+	Short: "Generate a new stack of commands from a goal",
+	Long: `Ask your local Ollama model for the ordered commands that achieve a goal,
+review them, and pin them into a new stack.
+
+The first step is stored at index 0, so 'cam ls <stack>' reads top to bottom
+like a runbook. In a terminal the steps can be edited, deleted or regenerated
+before saving; use --yes to save without review.
+
+Examples:
+  cam gen-stack go-service "set up a new Go service with docker and CI"
+  cam gen-stack release --ctx git,readme "cut a new release of this project"`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackName := args[0]
+		goal := strings.Join(args[1:], " ")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+
+		store := data.NewDataStore()
+		// Load private commands too so a stack holding only those counts as existing.
+		if err := store.LoadData(true); err != nil {
+			return fmt.Errorf("failed to load data store: %w", err)
+		}
+		if len(store.GetStack(stackName)) > 0 {
+			return fmt.Errorf("stack '%s' already exists; choose another name or remove it with 'cam rm %s'", stackName, stackName)
+		}
+
+		configStore := data.NewConfigStore()
+		if err := configStore.LoadConfig(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		ctx := aiContext(cmd, data.TaskCmdr)
+		contextStr, err := buildContext(cmd, configStore, nil)
+		if err != nil {
+			return err
+		}
+
+		generate := func(ctx context.Context) ([]step, error) {
+			return generateSteps(ctx, configStore, goal, contextStr)
+		}
+
+		steps, err := generate(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !assumeYes {
+			if !isInteractive() {
+				printSteps(steps)
+				return fmt.Errorf("not saved: review the steps in a terminal or pass --yes")
+			}
+			steps, err = reviewSteps(ctx, steps, generate)
+			if errors.Is(err, errCancelled) {
+				fmt.Println("Aborted.")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// Save into freshly loaded stacks: others may have changed while reviewing.
+		store = data.NewDataStore()
+		err = store.Update(func() error {
+			if len(store.GetStack(stackName)) > 0 {
+				return fmt.Errorf("stack '%s' was created meanwhile; not saved", stackName)
+			}
+			// Stacks are prepended to, so add the last step first.
+			for i := len(steps) - 1; i >= 0; i-- {
+				if err := store.AddCommand(stackName, steps[i].Command, steps[i].Description, nil, false); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(successStyle.Render(fmt.Sprintf("✔ Pinned %d commands to '%s'", len(steps), stackName)))
+		return nil
+	},
+}
+
+// generateSteps asks the model for the ordered commands achieving goal, using
+// structured output when available and "<command> ## <description>" lines otherwise.
+func generateSteps(ctx context.Context, configStore *data.ConfigStore, goal string, contextStr string) ([]step, error) {
+	vars := prompts.NewVars(goal, contextStr)
+	vars.Format = "json"
+	prompt, err := prompts.Render("stack", vars)
+	if err != nil {
+		return nil, err
+	}
+
+	resultText, err := ai.GenerateJSON(ctx, configStore, prompt, stepsSchema)
+	if err != nil && !errors.Is(err, ai.ErrStructuredUnsupported) {
+		return nil, err
+	}
+	if err == nil {
+		steps, parseErr := parseSteps(resultText)
+		if parseErr == nil {
+			return steps, nil
+		}
+		fmt.Fprintf(os.Stderr, "Warning: ignoring malformed structured answer (%v)\n", parseErr)
+	}
+
+	vars.Format = ""
+	prompt, err = prompts.Render("stack", vars)
+	if err != nil {
+		return nil, err
+	}
+
+	resultText, err = ai.GenerateContent(ctx, configStore, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []step
+	for _, c := range parseCandidates(resultText) {
+		steps = append(steps, step{Command: c.Command, Description: c.Explanation})
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("AI response contained no usable commands. Raw: %s", resultText)
+	}
+	return steps, nil
+}
+
+// parseSteps strictly decodes a structured gen-stack answer.
+func parseSteps(resultText string) ([]step, error) {
+	dec := json.NewDecoder(strings.NewReader(resultText))
+	dec.DisallowUnknownFields()
+
+	var answer struct {
+		Steps []step `json:"steps"`
+	}
+	if err := dec.Decode(&answer); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var steps []step
+	for _, s := range answer.Steps {
+		s.Command = strings.TrimSpace(s.Command)
+		s.Description = strings.TrimSpace(s.Description)
+		if s.Command != "" {
+			steps = append(steps, s)
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no steps")
+	}
+	return steps, nil
+}
+
+// printSteps shows the steps as a numbered list with any safety findings.
+func printSteps(steps []step) {
+	for i, s := range steps {
+		fmt.Printf("%d) %s\n", i+1, s.Command)
+		if s.Description != "" {
+			fmt.Println("   " + explanationStyle.Render(s.Description))
+		}
+		for _, r := range safety.Analyze(s.Command) {
+			fmt.Println("   " + warningStyle.Render("⚠ "+r.Reason))
+		}
+	}
+}
+
+// reviewSteps lets the user edit, delete or regenerate steps until they are
+// saved. It returns errCancelled if the user quits.
+func reviewSteps(ctx context.Context, steps []step, regenerate func(context.Context) ([]step, error)) ([]step, error) {
+	for {
+		printSteps(steps)
+
+		choice, err := promptLine("[s]ave  [e]dit <n>  [d]elete <n>  [g]enerate again  [q]uit: ")
+		if err != nil {
+			return nil, errCancelled
+		}
+
+		action, arg, _ := strings.Cut(strings.ToLower(choice), " ")
+		index := -1
+		if n, err := strconv.Atoi(strings.TrimSpace(arg)); err == nil && n >= 1 && n <= len(steps) {
+			index = n - 1
+		}
+
+		switch action {
+		case "s", "save":
+			if len(steps) == 0 {
+				fmt.Println("Nothing to save.")
+				continue
+			}
+			return steps, nil
+
+		case "e", "edit":
+			if index < 0 {
+				fmt.Printf("Usage: e <1-%d>\n", len(steps))
+				continue
+			}
+			fmt.Printf("Current: %s\n", steps[index].Command)
+			edited, err := promptLine("New command (enter to keep): ")
+			if err != nil {
+				return nil, errCancelled
+			}
+			if edited != "" {
+				steps[index].Command = edited
+			}
+			desc, err := promptLine("New description (enter to keep): ")
+			if err != nil {
+				return nil, errCancelled
+			}
+			if desc != "" {
+				steps[index].Description = desc
+			}
+
+		case "d", "delete":
+			if index < 0 {
+				fmt.Printf("Usage: d <1-%d>\n", len(steps))
+				continue
+			}
+			steps = append(steps[:index], steps[index+1:]...)
+
+		case "g", "generate", "regenerate":
+			regenerated, err := regenerate(ai.WithoutCache(ctx))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to regenerate: %v\n", err)
+				continue
+			}
+			steps = regenerated
+
+		case "", "q", "quit", "cancel":
+			return nil, errCancelled
+
+		default:
+			fmt.Printf("Unknown action '%s'\n", choice)
+		}
+	}
+}
+
+func init() {
+	genStackCmd.Flags().BoolP("yes", "y", false, "Save the generated commands without review")
+	genStackCmd.Flags().Bool("no-cache", false, "Bypass the AI response cache")
+	contextFlags(genStackCmd)
+	rootCmd.AddCommand(genStackCmd)
+}
@@ -0,0 +1,508 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cam/internal/bundle"
+	"cam/internal/crypto"
+	"cam/internal/data"
+	"cam/internal/history"
+	"cam/internal/interop"
+
+	"github.com/spf13/cobra"
+)
+
+// trivialCommands are too common to be worth suggesting from history.
+var trivialCommands = map[string]bool{
+	"ls": true, "ll": true, "la": true, "l": true, "cd": true, "pwd": true, "clear": true,
+	"exit": true, "history": true, "..": true, "fg": true, "bg": true, "jobs": true,
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Import a bundle, shell history or other snippet managers",
+	Long: `Import the stacks of a bundle written by 'cam export' ("-" reads stdin).
+
+--strategy decides what happens to stacks you already have:
+
+  skip     add only the commands the stack doesn't have yet (default)
+  append   add every command, duplicates included
+  replace  make the stack exactly the bundle's commands
+
+--dry-run shows what would change without saving. Private commands are
+decrypted with your key when the bundle was made for you ('cam keys public');
+--skip-private imports only the public ones.
+
+Signed bundles ('cam export --sign') are verified first; a bundle whose
+signature doesn't match is refused. Importing an unsigned bundle, or one
+signed by a key you haven't trusted ('cam keys trust'), needs confirmation
+or --yes.
+
+Subcommands import from shell history and other snippet managers.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+		strategyName, _ := cmd.Flags().GetString("strategy")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		skipPrivate, _ := cmd.Flags().GetBool("skip-private")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+
+		strategy, err := bundle.ParseStrategy(strategyName)
+		if err != nil {
+			return err
+		}
+		b, err := bundle.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		if ok, err := confirmSigner(b, assumeYes || dryRun); !ok {
+			return err
+		}
+
+		// The changes are worked out and saved under the data lock, so nothing
+		// pinned meanwhile is lost.
+		var changes []bundle.Change
+		var summary string
+		store := data.NewDataStore()
+		err = store.Update(func() error {
+			keysDir := ""
+			if _, private := b.Count(); private > 0 && !skipPrivate {
+				if keysDir, err = bundleKeys(store, b); err != nil {
+					return err
+				}
+			}
+
+			for _, s := range b.Stacks {
+				if store.IsReadOnly(s.Name) {
+					return fmt.Errorf("stack '%s' is read-only and can't be imported into", s.Name)
+				}
+				incoming, err := s.DataCommands(keysDir)
+				if err != nil {
+					return err
+				}
+				changes = append(changes, bundle.Merge(s.Name, store.Stacks[s.Name], incoming, strategy))
+			}
+
+			added, duplicates, removed := 0, 0, 0
+			for _, c := range changes {
+				printChange(c, dryRun)
+				added += len(c.Added)
+				duplicates += len(c.Duplicates)
+				removed += len(c.Removed)
+			}
+
+			summary = fmt.Sprintf("%d added", added)
+			if duplicates > 0 {
+				summary += fmt.Sprintf(", %d already saved", duplicates)
+			}
+			if removed > 0 {
+				summary += fmt.Sprintf(", %d removed", removed)
+			}
+			if dryRun {
+				return errDryRun
+			}
+
+			for _, c := range changes {
+				if c.Changed() {
+					if err := store.SetStack(c.Stack, c.Result); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if errors.Is(err, errDryRun) {
+			fmt.Printf("Dry run: %s. Nothing was changed.\n", summary)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(successStyle.Render(fmt.Sprintf("✔ Imported %d stacks: %s", len(changes), summary)))
+		return nil
+	},
+}
+
+// errDryRun stops a dry-run import before anything is saved.
+var errDryRun = errors.New("dry run")
+
+// confirmSigner verifies the bundle's signature and, unless it was made by a
+// trusted signer, asks whether to import it anyway. It returns false with a
+// nil error if the user declined.
+func confirmSigner(b *bundle.Bundle, assumeYes bool) (bool, error) {
+	signer, err := b.Verify()
+	if err != nil && !errors.Is(err, bundle.ErrUnsigned) {
+		return false, err
+	}
+	label, trusted := signerLabel(signer)
+	if trusted {
+		fmt.Println(successStyle.Render("✔ Bundle " + label))
+		return true, nil
+	}
+
+	fmt.Fprintln(os.Stderr, warningStyle.Render("⚠ Bundle is "+label))
+	if assumeYes {
+		return true, nil
+	}
+	if !isInteractive() {
+		return false, fmt.Errorf("refusing to import a bundle that isn't signed by a trusted signer without confirmation (use --yes)")
+	}
+	if !confirm("Import anyway?", false) {
+		fmt.Println("Aborted.")
+		return false, nil
+	}
+	return true, nil
+}
+
+// bundleKeys returns the keys directory to decrypt the bundle's private
+// commands with, after checking they were encrypted for this profile's key.
+func bundleKeys(store *data.DataStore, b *bundle.Bundle) (string, error) {
+	keysDir, err := store.EnsureKeys()
+	if err != nil {
+		return "", err
+	}
+	fingerprint, err := crypto.Fingerprint(filepath.Join(keysDir, "public_key.pem"))
+	if err != nil {
+		return "", err
+	}
+	if b.Recipient != fingerprint {
+		return "", fmt.Errorf("the private commands in this bundle are encrypted for %s, but your key is %s (use --skip-private to import only public commands)", b.Recipient, fingerprint)
+	}
+	return keysDir, nil
+}
+
+// printChange lists the commands an import adds to or removes from a stack.
+// Only the stack's totals are shown unless verbose.
+func printChange(c bundle.Change, verbose bool) {
+	label := c.Stack
+	if c.New {
+		label += " (new)"
+	}
+	fmt.Printf("%s: +%d", label, len(c.Added))
+	if len(c.Removed) > 0 {
+		fmt.Printf(" -%d", len(c.Removed))
+	}
+	if len(c.Duplicates) > 0 {
+		fmt.Printf(", %d already saved", len(c.Duplicates))
+	}
+	fmt.Println()
+	if !verbose {
+		return
+	}
+
+	show := func(c data.Command) string {
+		if c.IsPrivate {
+			return "[private]"
+		}
+		return strings.ReplaceAll(c.Cmd, "\n", " ⏎ ")
+	}
+	for _, r := range c.Removed {
+		fmt.Println(removedStyle.Render("  - " + show(r)))
+	}
+	for _, a := range c.Added {
+		fmt.Println(addedStyle.Render("  + " + show(a)))
+	}
+	for _, d := range c.Duplicates {
+		fmt.Println(explanationStyle.Render("  = " + show(d)))
+	}
+}
+
+var importHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Pick frequently used commands from your shell history",
+	Long: `Read your shell history, rank commands by how often you ran them and pick
+the ones to pin into stacks.
+
+bash, zsh (including extended history with timestamps) and fish are
+supported. --since limits the import to recent commands (e.g. 7d, 2w, 12h)
+and needs a history with timestamps. Trivial commands (ls, cd, ...), cam
+itself and commands already in your stacks are skipped.
+
+Select entries with numbers and ranges ("1 3 5-7", "a" for all), then name
+the stack to pin them into; repeat to fill several stacks.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell, _ := cmd.Flags().GetString("shell")
+		file, _ := cmd.Flags().GetString("file")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		limit, _ := cmd.Flags().GetInt("limit")
+		stackName, _ := cmd.Flags().GetString("stack")
+
+		if shell == "" {
+			shell = history.DetectShell()
+		}
+
+		var entries []history.Entry
+		var err error
+		if file != "" {
+			entries, err = history.LoadFile(shell, file)
+		} else {
+			entries, err = history.Load(shell)
+		}
+		if err != nil {
+			return err
+		}
+
+		if sinceStr != "" {
+			age, err := parseAge(sinceStr)
+			if err != nil {
+				return err
+			}
+			recent := history.Since(entries, time.Now().Add(-age))
+			if len(recent) == 0 && !hasTimestamps(entries) {
+				return fmt.Errorf("%s history has no timestamps, so --since can't be used (for bash, set HISTTIMEFORMAT)", shell)
+			}
+			entries = recent
+		}
+
+		store := data.NewDataStore()
+		if err := store.LoadData(true); err != nil {
+			return fmt.Errorf("failed to load data store: %w", err)
+		}
+
+		candidates := importableCommands(history.Rank(entries), store)
+		if limit > 0 && len(candidates) > limit {
+			candidates = candidates[:limit]
+		}
+		if len(candidates) == 0 {
+			fmt.Println("No new commands found in history.")
+			return nil
+		}
+
+		if !isInteractive() {
+			for _, c := range candidates {
+				fmt.Printf("%4d  %s\n", c.Count, c.Command)
+			}
+			return nil
+		}
+
+		pinned := 0
+		for len(candidates) > 0 {
+			for i, c := range candidates {
+				fmt.Printf("%3d) %s %s\n", i+1, explanationStyle.Render(fmt.Sprintf("%4dx", c.Count)), c.Command)
+			}
+
+			choices, err := pickMany(len(candidates))
+			if errors.Is(err, errCancelled) {
+				break
+			}
+
+			target := stackName
+			if target == "" {
+				if target, err = promptLine("Stack: "); err != nil || target == "" {
+					break
+				}
+			}
+
+			fresh := data.NewDataStore()
+			err = fresh.Update(func() error {
+				// Stacks are prepended to, so add the last choice first to keep the ranking.
+				for i := len(choices) - 1; i >= 0; i-- {
+					if err := fresh.AddCommand(target, candidates[choices[i]].Command, "", nil, false); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Println(successStyle.Render(fmt.Sprintf("✔ Pinned %d commands to '%s'", len(choices), target)))
+			pinned += len(choices)
+
+			var remaining []history.Ranked
+			for i, c := range candidates {
+				if !slices.Contains(choices, i) {
+					remaining = append(remaining, c)
+				}
+			}
+			candidates = remaining
+		}
+
+		if pinned > 0 {
+			fmt.Printf("Imported %d commands. Add descriptions and tags with 'cam enrich <stack>'.\n", pinned)
+		}
+		return nil
+	},
+}
+
+// importableCommands drops trivial commands, cam itself and anything already saved.
+func importableCommands(ranked []history.Ranked, store *data.DataStore) []history.Ranked {
+	saved := make(map[string]bool)
+	for _, commands := range store.Stacks {
+		for _, c := range commands {
+			saved[strings.TrimSpace(c.Cmd)] = true
+		}
+	}
+
+	var result []history.Ranked
+	for _, r := range ranked {
+		fields := strings.Fields(r.Command)
+		if len(fields) == 0 || saved[r.Command] {
+			continue
+		}
+		if len(fields) == 1 && trivialCommands[fields[0]] {
+			continue
+		}
+		if filepath.Base(fields[0]) == "cam" {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func hasTimestamps(entries []history.Entry) bool {
+	for _, e := range entries {
+		if !e.Time.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAge parses a duration that may also use days and weeks, e.g. "7d" or "2w".
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if v, err := strconv.Atoi(n); err == nil && v > 0 {
+				return time.Duration(v) * unit, nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid --since value '%s' (e.g. 7d, 2w, 12h)", s)
+}
+
+// newImportFormatCmd builds `cam import <format>` for another snippet manager's files.
+func newImportFormatCmd(f interop.Format) *cobra.Command {
+	c := &cobra.Command{
+		Use:   f.Name + " [file|dir...]",
+		Short: "Import " + f.Description,
+		Long: fmt.Sprintf(`Import %s into cam stacks.
+
+Directories are searched for %s files.%s Descriptions and tags are kept,
+and placeholders become cam's <name> or <name=default>. Commands already in
+the target stack are skipped.
+
+Stacks come from the file itself (%s); --stack puts everything into one stack.`,
+			f.Description, strings.Join(f.Exts, "/"), defaultPathNote(f), f.StackSource),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stackName, _ := cmd.Flags().GetString("stack")
+
+			paths := args
+			if len(paths) == 0 {
+				if f.DefaultPath == nil {
+					return fmt.Errorf("give the %s files or directories to import", f.Name)
+				}
+				path, err := f.DefaultPath()
+				if err != nil {
+					return fmt.Errorf("failed to locate %s snippets: %w", f.Name, err)
+				}
+				paths = []string{path}
+			}
+
+			snippets, err := f.ReadFiles(paths)
+			if err != nil {
+				return err
+			}
+			for i := range snippets {
+				if stackName != "" {
+					snippets[i].Stack = stackName
+				} else if snippets[i].Stack == "" {
+					snippets[i].Stack = f.Name
+				}
+			}
+
+			added, skipped := 0, 0
+			counts := make(map[string]int)
+			store := data.NewDataStore()
+			err = store.Update(func() error {
+				// Stacks are prepended to, so add in reverse to keep the file's order.
+				for i := len(snippets) - 1; i >= 0; i-- {
+					s := snippets[i]
+					if hasCommand(store, s.Stack, s.Command) {
+						skipped++
+						continue
+					}
+					if err := store.AddCommand(s.Stack, s.Command, s.Description, s.Tags, false); err != nil {
+						return err
+					}
+					counts[s.Stack]++
+					added++
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			stacks := make([]string, 0, len(counts))
+			for name := range counts {
+				stacks = append(stacks, name)
+			}
+			sort.Strings(stacks)
+			for _, name := range stacks {
+				fmt.Printf("  %s: %d\n", name, counts[name])
+			}
+			msg := fmt.Sprintf("✔ Imported %d commands into %d stacks", added, len(stacks))
+			if skipped > 0 {
+				msg += fmt.Sprintf(" (%d already saved)", skipped)
+			}
+			fmt.Println(successStyle.Render(msg))
+			return nil
+		},
+	}
+	c.Flags().String("stack", "", "import everything into this stack")
+	return c
+}
+
+// hasCommand reports whether stack already holds command.
+func hasCommand(store *data.DataStore, stack, command string) bool {
+	for _, c := range store.Stacks[stack] {
+		if strings.TrimSpace(c.Cmd) == strings.TrimSpace(command) {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultPathNote(f interop.Format) string {
+	if f.DefaultPath == nil {
+		return ""
+	}
+	path, err := f.DefaultPath()
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\nWithout arguments, %s is read.", path)
+}
+
+func init() {
+	importHistoryCmd.Flags().String("shell", "", "history format: bash, zsh or fish (default: your shell)")
+	importHistoryCmd.Flags().String("file", "", "history file to read (default: the shell's history file)")
+	importHistoryCmd.Flags().String("since", "", "only commands run within this period, e.g. 7d, 2w, 12h")
+	importHistoryCmd.Flags().IntP("limit", "n", 30, "number of commands to offer")
+	importHistoryCmd.Flags().String("stack", "", "pin every selection into this stack without asking")
+	importCmd.AddCommand(importHistoryCmd)
+	for _, f := range interop.Formats() {
+		importCmd.AddCommand(newImportFormatCmd(f))
+	}
+	importCmd.Flags().String("strategy", string(bundle.Skip), "how to merge into existing stacks: skip, append or replace")
+	importCmd.Flags().Bool("dry-run", false, "show what would change without saving")
+	importCmd.Flags().Bool("skip-private", false, "leave out private commands")
+	importCmd.Flags().BoolP("yes", "y", false, "import unsigned or untrusted bundles without confirmation")
+	rootCmd.AddCommand(importCmd)
+}
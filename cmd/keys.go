@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"cam/internal/crypto"
+	"cam/internal/data"
+
+	"github.com/spf13/cobra"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage the keys used for private commands and signing",
+}
+
+var keysPublicCmd = &cobra.Command{
+	Use:   "public",
+	Short: "Print your public key for others to encrypt bundles to",
+	Long: `Print the public key of the active profile, creating the key pair if needed.
+
+Send it to a colleague so they can share private commands with you:
+
+  cam keys public > me.pem                   # you
+  cam export --recipient me.pem -o git.json  # them
+  cam import git.json                        # you`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signing, _ := cmd.Flags().GetBool("signing")
+		store := data.NewDataStore()
+		keysDir, err := store.EnsureKeys()
+		if err != nil {
+			return err
+		}
+
+		if signing {
+			priv, err := crypto.LoadSigningKey(keysDir)
+			if err != nil {
+				return err
+			}
+			key := crypto.FormatPublicKey(priv.Public().(ed25519.PublicKey))
+			fmt.Println(key)
+			fmt.Fprintf(os.Stderr, "Fingerprint: %s\n", crypto.SigningFingerprint(key))
+			return nil
+		}
+
+		pubKeyPath := filepath.Join(keysDir, "public_key.pem")
+		pem, err := os.ReadFile(pubKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to read public key: %w", err)
+		}
+		fingerprint, err := crypto.Fingerprint(pubKeyPath)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(string(pem))
+		fmt.Fprintf(os.Stderr, "Fingerprint: %s\n", fingerprint)
+		return nil
+	},
+}
+
+var keysSignInitCmd = &cobra.Command{
+	Use:   "sign-init",
+	Short: "Create the key used to sign bundles",
+	Long: `Create an Ed25519 signing key for 'cam export --sign'.
+
+Others trust your bundles and sources by adding your public signing key:
+
+  cam keys public --signing              # you
+  cam keys trust alice ed25519:MCow...   # them`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		store := data.NewDataStore()
+		keysDir, err := store.EnsureKeys()
+		if err != nil {
+			return err
+		}
+		if err := crypto.GenerateSigningKey(keysDir, force); err != nil {
+			return err
+		}
+		priv, err := crypto.LoadSigningKey(keysDir)
+		if err != nil {
+			return err
+		}
+
+		key := crypto.FormatPublicKey(priv.Public().(ed25519.PublicKey))
+		fmt.Println(successStyle.Render("✔ Created a signing key"))
+		fmt.Printf("Public key: %s\n", key)
+		fmt.Println(explanationStyle.Render("Share it so others can 'cam keys trust' your signed bundles."))
+		return nil
+	},
+}
+
+var keysTrustCmd = &cobra.Command{
+	Use:   "trust <name> <key|file>",
+	Short: "Trust bundles and sources signed by a key",
+	Long: `Add a signer to the trusted signers. The key is the line printed by
+'cam keys public --signing' ("ed25519:..."), given directly or in a file.
+Bundles and sources signed by trusted signers are imported and run without
+extra confirmation.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, key := args[0], args[1]
+		if content, err := os.ReadFile(key); err == nil {
+			key = strings.TrimSpace(string(content))
+		}
+		if _, err := crypto.ParsePublicKey(key); err != nil {
+			return err
+		}
+
+		signers, err := data.TrustedSigners()
+		if err != nil {
+			return err
+		}
+		for _, s := range signers {
+			if s.Name == name {
+				return fmt.Errorf("a signer named '%s' already exists", name)
+			}
+			if s.Key == key {
+				return fmt.Errorf("this key is already trusted as '%s'", s.Name)
+			}
+		}
+
+		signers = append(signers, data.Signer{Name: name, Key: key, Added: time.Now().Format(time.RFC3339)})
+		if err := data.SaveTrustedSigners(signers); err != nil {
+			return err
+		}
+		fmt.Println(successStyle.Render(fmt.Sprintf("✔ Trusting '%s' (%s)", name, crypto.SigningFingerprint(key))))
+		return nil
+	},
+}
+
+var keysUntrustCmd = &cobra.Command{
+	Use:   "untrust <name>",
+	Short: "Remove a trusted signer",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signers, err := data.TrustedSigners()
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(signers, func(s data.Signer) bool { return s.Name == args[0] })
+		if i < 0 {
+			return fmt.Errorf("signer '%s' not found", args[0])
+		}
+		if err := data.SaveTrustedSigners(slices.Delete(signers, i, i+1)); err != nil {
+			return err
+		}
+		fmt.Printf("No longer trusting '%s'\n", args[0])
+		return nil
+	},
+}
+
+var keysTrustedCmd = &cobra.Command{
+	Use:   "trusted",
+	Short: "List trusted signers",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signers, err := data.TrustedSigners()
+		if err != nil {
+			return err
+		}
+		if len(signers) == 0 {
+			fmt.Println("No trusted signers. Add one with 'cam keys trust <name> <key>'.")
+			return nil
+		}
+		for _, s := range signers {
+			fmt.Printf("%s %s\n", s.Name, crypto.SigningFingerprint(s.Key))
+			fmt.Println(explanationStyle.Render("  " + s.Key))
+		}
+		return nil
+	},
+}
+
+// signerLabel describes who signed something, given the signer's public key
+// (empty when unsigned).
+func signerLabel(key string) (string, bool) {
+	if key == "" {
+		return "unsigned", false
+	}
+	if s, ok := data.TrustedSigner(key); ok {
+		return fmt.Sprintf("signed by '%s'", s.Name), true
+	}
+	return fmt.Sprintf("signed by an untrusted key (%s)", crypto.SigningFingerprint(key)), false
+}
+
+func init() {
+	keysPublicCmd.Flags().Bool("signing", false, "Print the public signing key instead")
+	keysSignInitCmd.Flags().Bool("force", false, "Replace an existing signing key")
+	keysCmd.AddCommand(keysPublicCmd, keysSignInitCmd, keysTrustCmd, keysUntrustCmd, keysTrustedCmd)
+	rootCmd.AddCommand(keysCmd)
+}
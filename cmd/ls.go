@@ -78,7 +78,11 @@ Use -p to list private stacks or commands.`,
 		foundAnyCmd := false
 		for i, item := range stack {
 			if shouldShow(item) {
-				fmt.Printf("[%d] %s\n", i, item.Cmd)
+				if item.Description != "" {
+					fmt.Printf("[%d] %s  # %s\n", i, item.Cmd, item.Description)
+				} else {
+					fmt.Printf("[%d] %s\n", i, item.Cmd)
+				}
 				foundAnyCmd = true
 			}
 		}
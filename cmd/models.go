@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"cam/internal/ai"
+	"cam/internal/data"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List and download Ollama models",
+	Long: `List and download the Ollama models cam can use.
+
+Each task can use its own model:
+  cam config model qwen2.5-coder:7b       # default for every task
+  cam config ask-model llama3             # explanations (ask)
+  cam config cmdr-model qwen2.5-coder:7b  # command generation (cmdr, fix)
+  cam config embed-model nomic-embed-text # semantic search`,
+}
+
+var modelsLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List models installed in Ollama",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configStore := data.NewConfigStore()
+		if err := configStore.LoadConfig(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		models, err := ai.ListModels(context.Background())
+		if err != nil {
+			return err
+		}
+		if len(models) == 0 {
+			fmt.Println("No models installed. Download one with 'cam models pull <name>'.")
+			return nil
+		}
+
+		for _, m := range models {
+			line := fmt.Sprintf("%-32s %9s  %s", m.Name, formatSize(m.Size), m.ModifiedAt.Format("2006-01-02"))
+			if uses := modelUses(configStore, m.Name); len(uses) > 0 {
+				line += "  " + successStyle.Render("("+strings.Join(uses, ", ")+")")
+			}
+			fmt.Println(line)
+		}
+
+		// Point out configured models that aren't installed.
+		for _, task := range []string{data.TaskAsk, data.TaskCmdr, data.TaskEmbed} {
+			if name := configStore.GetTaskModel(task); !ai.HasModel(models, name) {
+				fmt.Fprintln(os.Stderr, warningStyle.Render(fmt.Sprintf("⚠ %s model '%s' is not installed (cam models pull %s)", task, name, name)))
+			}
+		}
+		return nil
+	},
+}
+
+var modelsPullCmd = &cobra.Command{
+	Use:   "pull <name>",
+	Short: "Download a model into Ollama",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		tty := term.IsTerminal(int(os.Stderr.Fd()))
+
+		var lastStatus string
+		err := ai.PullModel(context.Background(), name, func(p ai.PullProgress) {
+			if tty {
+				line := p.Status
+				if p.Total > 0 {
+					line = fmt.Sprintf("%s %3d%% (%s / %s)", p.Status, p.Completed*100/p.Total, formatSize(p.Completed), formatSize(p.Total))
+				}
+				fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+				return
+			}
+			// Without a terminal only print each status once.
+			if p.Status != lastStatus {
+				fmt.Fprintln(os.Stderr, p.Status)
+			}
+			lastStatus = p.Status
+		})
+		if tty {
+			fmt.Fprintln(os.Stderr)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(successStyle.Render(fmt.Sprintf("✔ Pulled '%s'", name)))
+		return nil
+	},
+}
+
+// modelUses lists the tasks configured to use model.
+func modelUses(configStore *data.ConfigStore, model string) []string {
+	var uses []string
+	for _, task := range []string{data.TaskAsk, data.TaskCmdr, data.TaskEmbed} {
+		if ai.HasModel([]ai.Model{{Name: model}}, configStore.GetTaskModel(task)) {
+			uses = append(uses, task)
+		}
+	}
+	return uses
+}
+
+// checkModelInstalled returns an error if model isn't installed locally. When
+// Ollama can't be reached the model is accepted with a warning.
+func checkModelInstalled(model string) error {
+	models, err := ai.ListModels(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not check installed models: %v\n", err)
+		return nil
+	}
+	if !ai.HasModel(models, model) {
+		return fmt.Errorf("model '%s' is not installed; run 'cam models pull %s' first, or use --force", model, model)
+	}
+	return nil
+}
+
+// formatSize renders a byte count in human readable units.
+func formatSize(bytes int64) string {
+	const unit = 1000
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "kMGTPE"[exp])
+}
+
+func init() {
+	modelsCmd.AddCommand(modelsLsCmd, modelsPullCmd)
+	rootCmd.AddCommand(modelsCmd)
+}
@@ -29,32 +29,22 @@ If no index is provided, defaults to the most recent command (index 0).`,
 		}
 
 		store := data.NewDataStore()
-		if err := store.LoadData(true); err != nil {
-			return fmt.Errorf("failed to load data: %w", err)
-		}
-
-		stack := store.GetStack(stackName)
-		if len(stack) == 0 {
-			return fmt.Errorf("stack '%s' is empty or does not exist", stackName)
-		}
-		if index < 0 || index >= len(stack) {
-			return fmt.Errorf("index %d out of bounds", index)
-		}
-
-		cmdStr := stack[index].Cmd
-		if err := clipboard.WriteAll(cmdStr); err != nil {
-			return fmt.Errorf("failed to copy to clipboard: %w", err)
-		}
-
-		if err := store.RemoveCommand(stackName, index); err != nil {
-			return err
-		}
+		return store.Update(func() error {
+			stack := store.GetStack(stackName)
+			if len(stack) == 0 {
+				return fmt.Errorf("stack '%s' is empty or does not exist", stackName)
+			}
+			if index < 0 || index >= len(stack) {
+				return fmt.Errorf("index %d out of bounds", index)
+			}
 
-		if err := store.SaveData(); err != nil {
-			return fmt.Errorf("failed to save data: %w", err)
-		}
+			cmdStr := stack[index].Cmd
+			if err := clipboard.WriteAll(cmdStr); err != nil {
+				return fmt.Errorf("failed to copy to clipboard: %w", err)
+			}
 
-		return nil
+			return store.RemoveCommand(stackName, index)
+		})
 	},
 }
 
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"cam/internal/ai"
 	"cam/internal/data"
 
 	"github.com/spf13/cobra"
@@ -15,31 +18,119 @@ var pinCmd = &cobra.Command{
 	Long: `Pin a command string to the specified stack.
 New commands are prepended to the stack (index 0).
 
-Use -p to store the command as an encrypted private command.`,
+Use -p to store the command as an encrypted private command.
+Use -d to add a short description and -t to add tags, which improve
+filtering and 'cam search' results. --auto asks your local model to suggest
+them (anything given with -d or -t is kept) and confirms before saving;
+-y accepts the suggestion without asking. Backfill existing stacks with
+'cam enrich <stack>'.
+
+Use --project to pin into the current project's .cam.json instead, so the
+command is versioned with the code and shows up for everyone as
+"project:<stack>". Without an existing project file, .cam.json is created
+at the root of the git repository (or in the current directory).`,
 	Args: cobra.MinimumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		stackName := args[0]
 		commandStr := strings.Join(args[1:], " ")
 		isPrivate, _ := cmd.Flags().GetBool("private")
+		description, _ := cmd.Flags().GetString("desc")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		auto, _ := cmd.Flags().GetBool("auto")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		toProject, _ := cmd.Flags().GetBool("project")
 
-		store := data.NewDataStore()
-		if err := store.LoadData(false); err != nil {
-			return fmt.Errorf("failed to load data store: %w", err)
+		if toProject && isPrivate {
+			return fmt.Errorf("project stacks are shared in plain text and can't hold private commands")
 		}
 
-		if err := store.AddCommand(stackName, commandStr, nil, isPrivate); err != nil {
-			return err
+		if auto {
+			description, tags = autoEnrich(cmd, stackName, commandStr, description, tags, isPrivate, assumeYes)
 		}
 
-		if err := store.SaveData(); err != nil {
-			return fmt.Errorf("failed to save data store: %w", err)
+		if toProject {
+			return pinToProject(strings.TrimPrefix(stackName, data.ProjectPrefix), commandStr, description, tags)
 		}
-
-		return nil
+		return pinToStack(stackName, commandStr, description, tags, isPrivate)
 	},
 }
 
+// autoEnrich fills in a suggested description and tags for pin --auto. Values
+// given on the command line are kept, and any failure just pins without them.
+func autoEnrich(cmd *cobra.Command, stackName, cmdStr, description string, tags []string, isPrivate, assumeYes bool) (string, []string) {
+	if !assumeYes && !isInteractive() {
+		fmt.Fprintln(os.Stderr, "Warning: --auto needs confirmation; pass --yes to accept suggestions without a terminal")
+		return description, tags
+	}
+
+	configStore := data.NewConfigStore()
+	if err := configStore.LoadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load config: %v\n", err)
+		return description, tags
+	}
+
+	var knownTags []string
+	store := data.NewDataStore()
+	if err := store.LoadData(false); err == nil {
+		knownTags = collectTags(store)
+	}
+
+	ctx := aiContext(cmd, data.TaskCmdr)
+	if isPrivate {
+		ctx = ai.WithoutCache(ctx) // keep private commands out of the response cache
+	}
+
+	suggestion, err := suggestEnrichment(ctx, configStore, stackName, cmdStr, knownTags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: no suggestion: %v\n", err)
+		return description, tags
+	}
+	mergeEnrichment(&suggestion, data.Command{Description: description, Tags: tags}, false)
+
+	if !assumeYes {
+		if ok, _ := reviewEnrichment(cmdStr, &suggestion); !ok {
+			return description, tags
+		}
+	}
+	return suggestion.Description, suggestion.Tags
+}
+
+// pinToStack prepends cmdStr to the named stack and persists the store.
+func pinToStack(stackName string, cmdStr string, description string, tags []string, isPrivate bool) error {
+	store := data.NewDataStore()
+	return store.Update(func() error {
+		return store.AddCommand(stackName, cmdStr, description, tags, isPrivate)
+	})
+}
+
+// pinToProject prepends cmdStr to a stack of the project around the working directory.
+func pinToProject(stackName string, cmdStr string, description string, tags []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	project, found := data.FindProject(cwd)
+	if !found {
+		project = data.NewProject(cwd)
+	}
+	if err := project.AddCommand(stackName, cmdStr, description, tags); err != nil {
+		return err
+	}
+
+	if !found {
+		fmt.Printf("Created %s\n", filepath.Join(project.Root, data.ProjectFile))
+	}
+	return nil
+}
+
 func init() {
 	pinCmd.Flags().BoolP("private", "p", false, "encrypt command and store as private")
+	pinCmd.Flags().StringP("desc", "d", "", "short description of the command")
+	pinCmd.Flags().StringSliceP("tag", "t", nil, "tag the command (repeatable or comma separated)")
+	pinCmd.Flags().Bool("auto", false, "suggest a description and tags with the local model")
+	pinCmd.Flags().BoolP("yes", "y", false, "accept --auto suggestions without asking")
+	pinCmd.Flags().Bool("no-cache", false, "bypass the AI response cache")
+	pinCmd.Flags().Bool("project", false, "pin to the current project's .cam.json")
 	rootCmd.AddCommand(pinCmd)
 }
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"cam/internal/data"
+
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage profiles with separate data, keys and config",
+	Long: `Profiles keep sets of stacks strictly apart, e.g. work and personal. Each
+profile has its own data, encryption keys, config and prompt overrides.
+
+The profile in use is chosen by --profile, then $CAM_PROFILE, then
+'cam profile use'. The "default" profile is cam's top-level data directory.
+
+Examples:
+  cam profile create work
+  cam --profile work pin k8s "kubectl get pods -A"
+  cam profile use work`,
+}
+
+var profileLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := data.Profiles()
+		if err != nil {
+			return err
+		}
+
+		active := data.ActiveProfile()
+		for _, name := range profiles {
+			if name == active {
+				fmt.Println(successStyle.Render("* " + name))
+			} else {
+				fmt.Println("  " + name)
+			}
+		}
+		return nil
+	},
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := data.CreateProfile(name); err != nil {
+			return err
+		}
+
+		fmt.Println(successStyle.Render(fmt.Sprintf("✔ Created profile '%s'", name)))
+		fmt.Printf("Use it with 'cam --profile %s ...' or make it the default with 'cam profile use %s'\n", name, name)
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch the profile used by default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := data.UseProfile(name); err != nil {
+			return err
+		}
+
+		fmt.Printf("Now using profile '%s'\n", name)
+		return nil
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileLsCmd, profileCreateCmd, profileUseCmd)
+	rootCmd.AddCommand(profileCmd)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// stdinReader is shared so buffered input isn't lost between prompts.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// isInteractive reports whether both stdin and stdout are attached to a terminal.
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// promptLine prints a label and reads a single trimmed line from stdin.
+func promptLine(label string) (string, error) {
+	fmt.Print(label)
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// confirm asks a yes/no question, returning def when the user just presses enter.
+func confirm(question string, def bool) bool {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+
+	answer, err := promptLine(fmt.Sprintf("%s %s ", question, hint))
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(answer) {
+	case "":
+		return def
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// errCancelled is returned when the user backs out of a prompt.
+var errCancelled = errors.New("cancelled")
+
+// pickOne asks the user to choose an option numbered 1..n and returns its zero-based index.
+func pickOne(n int) (int, error) {
+	for {
+		answer, err := promptLine(fmt.Sprintf("Select [1-%d] (q to quit): ", n))
+		if err != nil {
+			return 0, errCancelled
+		}
+		if answer == "" || strings.EqualFold(answer, "q") {
+			return 0, errCancelled
+		}
+
+		choice, err := strconv.Atoi(answer)
+		if err == nil && choice >= 1 && choice <= n {
+			return choice - 1, nil
+		}
+		fmt.Printf("Please enter a number between 1 and %d\n", n)
+	}
+}
+
+// pickMany asks the user to choose any of the options numbered 1..n, accepting
+// lists and ranges such as "1 3 5-7" or "a" for all. It returns zero-based
+// indexes in ascending order.
+func pickMany(n int) ([]int, error) {
+	for {
+		answer, err := promptLine(fmt.Sprintf("Select [1-%d, e.g. 1 3 5-7, a for all] (q to quit): ", n))
+		if err != nil {
+			return nil, errCancelled
+		}
+		if answer == "" || strings.EqualFold(answer, "q") {
+			return nil, errCancelled
+		}
+
+		if choices, ok := parseSelection(answer, n); ok {
+			return choices, nil
+		}
+		fmt.Printf("Please enter numbers between 1 and %d\n", n)
+	}
+}
+
+// parseSelection parses a pickMany answer.
+func parseSelection(answer string, n int) ([]int, bool) {
+	selected := make([]bool, n)
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' }) {
+		if strings.EqualFold(field, "a") || strings.EqualFold(field, "all") {
+			for i := range selected {
+				selected[i] = true
+			}
+			continue
+		}
+
+		from, to, isRange := strings.Cut(field, "-")
+		if !isRange {
+			to = from
+		}
+		lo, err1 := strconv.Atoi(from)
+		hi, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil || lo < 1 || hi > n || lo > hi {
+			return nil, false
+		}
+		for i := lo; i <= hi; i++ {
+			selected[i-1] = true
+		}
+	}
+
+	var choices []int
+	for i, ok := range selected {
+		if ok {
+			choices = append(choices, i)
+		}
+	}
+	return choices, len(choices) > 0
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"cam/internal/prompts"
+
+	"github.com/spf13/cobra"
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Manage the AI prompt templates",
+	Long: `Manage the Go text/template prompts used by ask, cmdr (and cmdr -n via
+"candidates"), fix, gen-stack (via "stack") and pin --auto/enrich (via "enrich").
+
+Built-in defaults can be overridden per prompt in ~/.config/cam/prompts/<name>.tmpl,
+so prompts can be tuned per model without recompiling.
+
+Available variables:
+  {{.Question}}    the user's question or request
+  {{.Context}}     gathered context (may be empty)
+  {{.Shell}}       the user's shell, e.g. "zsh"
+  {{.OS}}          the operating system, e.g. "linux"
+  {{.Distro}}      the OS distribution and version, e.g. "Ubuntu 24.04 LTS"
+  {{.Coreutils}}   "GNU", "BSD" or "BusyBox"
+  {{.Tools}}       notable tools installed on PATH (use {{join .Tools ", "}})
+  {{.Unavailable}} executables a previous answer used that aren't installed
+  {{.Mode}}        "oneline" or "markdown" (ask)
+  {{.Format}}      "json" when output is constrained to a JSON schema (cmdr, fix)
+  {{.Count}}       number of alternatives requested (candidates)
+  {{.Examples}}    saved commands with .Command and .Description (cmdr)
+  {{.Failure}}     failed command with .Command, .ExitCode and .Stderr (fix)
+  {{.Stack}}       stack the command belongs to (enrich)
+  {{.Tags}}        tags already used in your stacks (enrich)`,
+}
+
+var promptsLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List prompt templates and whether they are customised",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, name := range prompts.Names() {
+			_, custom, err := prompts.Source(name)
+			if err != nil {
+				return err
+			}
+			if custom {
+				path, _ := prompts.OverridePath(name)
+				fmt.Printf("- %s (custom: %s)\n", name, path)
+			} else {
+				fmt.Printf("- %s (default)\n", name)
+			}
+		}
+		return nil
+	},
+}
+
+var promptsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print the effective template for a prompt",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		showDefault, _ := cmd.Flags().GetBool("default")
+
+		var src string
+		var err error
+		if showDefault {
+			src, err = prompts.Default(args[0])
+		} else {
+			src, _, err = prompts.Source(args[0])
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(src)
+		return nil
+	},
+}
+
+var promptsEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Open a prompt override in $EDITOR, creating it from the default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		src, _, err := prompts.Source(name)
+		if err != nil {
+			return err
+		}
+
+		path, err := prompts.OverridePath(name)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Errorf("failed to create prompts directory: %w", err)
+			}
+			if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+				return fmt.Errorf("failed to write prompt override: %w", err)
+			}
+		}
+
+		if err := openEditor(path); err != nil {
+			return err
+		}
+
+		edited, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt override: %w", err)
+		}
+		if err := prompts.Validate(name, string(edited)); err != nil {
+			return fmt.Errorf("saved, but the template is invalid (run 'cam prompts edit %s' again): %w", name, err)
+		}
+
+		fmt.Println(successStyle.Render(fmt.Sprintf("✔ Saved prompt '%s'", name)))
+		return nil
+	},
+}
+
+var promptsResetCmd = &cobra.Command{
+	Use:   "reset <name>",
+	Short: "Remove a prompt override and restore the default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if _, err := prompts.Default(name); err != nil {
+			return err
+		}
+
+		path, err := prompts.OverridePath(name)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove prompt override: %w", err)
+		}
+
+		fmt.Printf("Prompt '%s' reset to default\n", name)
+		return nil
+	},
+}
+
+// openEditor opens path in $VISUAL or $EDITOR, falling back to vi.
+func openEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Run through the shell so editors with arguments (e.g. "code -w") work.
+	execCmd := exec.Command("/bin/sh", "-c", editor+` "$1"`, "sh", path)
+	execCmd.Stdin = os.Stdin
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+	if err := execCmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	promptsShowCmd.Flags().Bool("default", false, "show the built-in default even if overridden")
+	promptsCmd.AddCommand(promptsLsCmd, promptsShowCmd, promptsEditCmd, promptsResetCmd)
+	rootCmd.AddCommand(promptsCmd)
+}
@@ -26,17 +26,13 @@ Use -a to remove ALL stacks.`,
 		deleteAll, _ := cmd.Flags().GetBool("all")
 
 		store := data.NewDataStore()
-		if err := store.LoadData(true); err != nil {
-			return fmt.Errorf("failed to load data: %w", err)
-		}
 
 		if deleteAll {
-			// Clear everything
-			store.Stacks = make(map[string][]data.Command)
-			if err := store.SaveData(); err != nil {
-				return fmt.Errorf("failed to save data: %w", err)
-			}
-			return nil
+			return store.Update(func() error {
+				// Clear everything
+				store.Stacks = make(map[string][]data.Command)
+				return nil
+			})
 		}
 
 		stackName := args[0]
@@ -47,20 +43,14 @@ Use -a to remove ALL stacks.`,
 				return fmt.Errorf("invalid index: %s", args[1])
 			}
 
-			if err := store.RemoveCommand(stackName, index); err != nil {
-				return err
-			}
-		} else {
-			if err := store.RemoveStack(stackName); err != nil {
-				return err
-			}
-		}
-
-		if err := store.SaveData(); err != nil {
-			return fmt.Errorf("failed to save data: %w", err)
+			return store.Update(func() error {
+				return store.RemoveCommand(stackName, index)
+			})
 		}
 
-		return nil
+		return store.Update(func() error {
+			return store.RemoveStack(stackName)
+		})
 	},
 }
 
@@ -3,6 +3,10 @@ package cmd
 import (
 	"os"
 
+	"cam/internal/data"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 )
 
@@ -11,6 +15,43 @@ var rootCmd = &cobra.Command{
 	Short: "Camel-case Command Manager",
 	Long: `cam is a CLI tool that acts as a persistent, indexed multi-clipboard
 for developers to store, retrieve, and execute common cli commands.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if home, _ := cmd.Flags().GetString("home"); home != "" {
+			data.SetHome(home)
+		}
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			data.SetProfile(profile)
+		}
+		if path, _ := cmd.Flags().GetString("config"); path != "" {
+			data.SetConfigPath(path)
+		}
+		applyConfig()
+		currentCommand = cmd.CommandPath()
+	},
+}
+
+// currentCommand is the command being run, e.g. "cam pin".
+var currentCommand = "cam"
+
+// applyConfig applies settings that affect every command. Errors are left to
+// the commands that actually load the config.
+func applyConfig() {
+	store := data.NewConfigStore()
+	if store.LoadConfig() != nil {
+		return
+	}
+
+	// ollama run and the HTTP API both honour OLLAMA_HOST.
+	if host := store.String(data.KeyOllamaHost); host != "" {
+		os.Setenv("OLLAMA_HOST", host)
+	}
+
+	switch store.String(data.KeyColor) {
+	case "never":
+		lipgloss.SetColorProfile(termenv.Ascii)
+	case "always":
+		lipgloss.SetColorProfile(termenv.TrueColor)
+	}
 }
 
 func Execute() {
@@ -21,4 +62,7 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().String("home", "", "directory for cam's config and data (default $CAM_HOME, then XDG dirs, then ~/.config/cam)")
+	rootCmd.PersistentFlags().String("profile", "", "profile to use (default $CAM_PROFILE, then 'cam profile use')")
+	rootCmd.PersistentFlags().String("config", "", "config file (default config.json of the profile)")
 }
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"cam/internal/data"
+	"cam/internal/safety"
 
 	"github.com/spf13/cobra"
 )
@@ -16,7 +17,12 @@ var runCmd = &cobra.Command{
 	Short: "Run a command from a stack",
 	Long: `Execute a command stored in a stack directly.
 If no index is provided, defaults to the most recent command (index 0).
-The command is executed in your default shell.`,
+The command is executed in your default shell.
+
+Commands matching destructive patterns (rm -rf, dd, mkfs, force pushes,
+chmod -R 777, curl | sh, overwriting redirections) require confirmation.
+Commands from sources that aren't signed by a trusted signer (see
+'cam keys trust') also need confirmation. Use --yes to skip confirmations.`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		stackName := args[0]
@@ -50,29 +56,106 @@ The command is executed in your default shell.`,
 			return fmt.Errorf("command at index %d is empty", index)
 		}
 
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		if ok, err := confirmSource(store, stackName, assumeYes); !ok {
+			return err
+		}
+		if ok, err := confirmSafe(cmdStr, assumeYes); !ok {
+			return err
+		}
+
 		fmt.Printf("Running: %s\n", cmdStr)
 
-		// Determine shell to use
-		shell := os.Getenv("SHELL")
-		if shell == "" {
-			shell = "/bin/sh"
-		}
+		return execShell(cmdStr)
+	},
+}
+
+// printRisks lists any destructive patterns found in cmdStr and reports whether there were any.
+func printRisks(cmdStr string) bool {
+	risks := safety.Analyze(cmdStr)
+	for _, r := range risks {
+		fmt.Fprintln(os.Stderr, warningStyle.Render("⚠ "+r.Reason))
+	}
+	return len(risks) > 0
+}
+
+// confirmSafe checks cmdStr for destructive patterns and asks for confirmation
+// when any are found. It returns false with a nil error if the user declined.
+func confirmSafe(cmdStr string, assumeYes bool) (bool, error) {
+	if !printRisks(cmdStr) || assumeYes {
+		return true, nil
+	}
 
-		// Execute command interactively
-		execCmd := exec.Command(shell, "-c", cmdStr)
-		execCmd.Stdout = os.Stdout
-		execCmd.Stderr = os.Stderr
-		execCmd.Stdin = os.Stdin
+	if !isInteractive() {
+		return false, fmt.Errorf("refusing to run risky command without confirmation (use --yes)")
+	}
 
-		if err := execCmd.Run(); err != nil {
-			// Don't duplicate the error print if the command itself failed and printed to stderr
-			return fmt.Errorf("command failed: %w", err)
+	if !confirm("Run anyway?", false) {
+		fmt.Println("Aborted.")
+		return false, nil
+	}
+	return true, nil
+}
+
+// confirmSource asks for confirmation before running a command from a
+// source that isn't signed by a trusted signer. It returns false with a nil
+// error if the user declined.
+func confirmSource(store *data.DataStore, stackName string, assumeYes bool) (bool, error) {
+	src, ok := store.SourceOf(stackName)
+	if !ok {
+		return true, nil
+	}
+	label, trusted := signerLabel(src.Signer)
+	if trusted {
+		return true, nil
+	}
+
+	fmt.Fprintln(os.Stderr, warningStyle.Render(fmt.Sprintf("⚠ '%s' comes from the source '%s', which is %s", stackName, src.Name, label)))
+	if assumeYes {
+		return true, nil
+	}
+	if !isInteractive() {
+		return false, fmt.Errorf("refusing to run a command from an untrusted source without confirmation (use --yes)")
+	}
+	if !confirm("Run anyway?", false) {
+		fmt.Println("Aborted.")
+		return false, nil
+	}
+	return true, nil
+}
+
+// execShell runs cmdStr interactively in the user's default shell.
+// commandShell returns the shell commands are run with: the 'shell' setting,
+// then $SHELL, then /bin/sh.
+func commandShell() string {
+	shell := os.Getenv("SHELL")
+	if store := data.NewConfigStore(); store.LoadConfig() == nil {
+		if configured := store.String(data.KeyShell); configured != "" {
+			shell = configured
 		}
+	}
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return shell
+}
 
-		return nil
-	},
+func execShell(cmdStr string) error {
+	// Execute command interactively
+	execCmd := exec.Command(commandShell(), "-c", cmdStr)
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+	execCmd.Stdin = os.Stdin
+
+	if err := execCmd.Run(); err != nil {
+		// Don't duplicate the error print if the command itself failed and printed to stderr
+		return fmt.Errorf("command failed: %w", err)
+	}
+
+	return nil
 }
 
 func init() {
+	runCmd.Flags().BoolP("yes", "y", false, "run risky commands and commands from untrusted sources without confirmation")
 	rootCmd.AddCommand(runCmd)
 }
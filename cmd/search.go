@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"cam/internal/data"
+	"cam/internal/search"
+
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Semantic search for a command across all stacks",
+	Long: `Search public commands by meaning rather than by characters, e.g.
+  cam search "delete merged branches"
+
+Commands (with their descriptions and tags) are embedded with the Ollama
+embedding model (see 'cam config embed-model') and ranked by cosine similarity
+to the query. Vectors are cached in the data directory, keyed by content hash.
+Falls back to fuzzy matching when no embedding model is available.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := strings.Join(args, " ")
+		limit, _ := cmd.Flags().GetInt("limit")
+		fuzzyOnly, _ := cmd.Flags().GetBool("fuzzy")
+
+		configStore := data.NewConfigStore()
+		if err := configStore.LoadConfig(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Private commands are loaded and then skipped, so the printed
+		// indexes match the positions 'cam run' uses.
+		store := data.NewDataStore()
+		if err := store.LoadData(true); err != nil {
+			return fmt.Errorf("failed to load data: %w", err)
+		}
+
+		var items []search.Item
+		for _, it := range search.Items(store.Stacks) {
+			if !it.Command.IsPrivate {
+				items = append(items, it)
+			}
+		}
+		if len(items) == 0 {
+			fmt.Println("No stacks found.")
+			return nil
+		}
+
+		var results []search.Result
+		semantic := !fuzzyOnly
+		if semantic {
+			var err error
+			results, err = search.Semantic(context.Background(), configStore, items, query)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: semantic search unavailable (%v); falling back to fuzzy matching\n", err)
+				semantic = false
+			}
+		}
+		if !semantic {
+			results = search.Fuzzy(items, query)
+		}
+
+		if len(results) == 0 {
+			fmt.Printf("No matches found for '%s'\n", query)
+			return nil
+		}
+
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+
+		for _, r := range results {
+			line := fmt.Sprintf("[%s] [%d] %s", r.Stack, r.Index, r.Command.Cmd)
+			if r.Command.Description != "" {
+				line += "  # " + r.Command.Description
+			}
+			if semantic {
+				line = fmt.Sprintf("%.2f %s", r.Score, line)
+			}
+			fmt.Println(line)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	searchCmd.Flags().IntP("limit", "n", 10, "maximum number of results")
+	searchCmd.Flags().Bool("fuzzy", false, "skip embeddings and use fuzzy matching")
+	rootCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"cam/internal/data"
+	"cam/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+// tokenFile holds the API token in the config directory.
+const tokenFile = "serve_token"
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve stacks as a local JSON API for editors and launchers",
+	Long: `Serve your stacks over HTTP so editor plugins and launchers can read and
+change them without running cam and parsing its output.
+
+Clients authenticate with "Authorization: Bearer <token>". The token is
+generated on first use and stored in serve_token in the config directory
+(readable only by you); --token uses a different one.
+
+  cam serve                        # http://127.0.0.1:7373
+  cam serve --socket ~/.cam.sock   # a Unix socket instead
+  curl -H "Authorization: Bearer $(cat ~/.config/cam/serve_token)" \
+       localhost:7373/v1/stacks
+
+Endpoints (stack names with "/" are written %2F):
+
+  GET    /v1/stacks                           list stacks
+  GET    /v1/stacks/{stack}                   a stack's commands
+  PUT    /v1/stacks/{stack}                   replace a stack
+  DELETE /v1/stacks/{stack}                   delete a stack
+  POST   /v1/stacks/{stack}/commands          pin a command at index 0
+  GET    /v1/stacks/{stack}/commands/{i}      a command
+  PATCH  /v1/stacks/{stack}/commands/{i}      change a command
+  DELETE /v1/stacks/{stack}/commands/{i}      remove a command
+  GET    /v1/search?q=...&mode=semantic       fuzzy (default) or semantic search
+  POST   /v1/render                           fill in <name=default> placeholders
+
+Private commands are listed without their text, and can't be changed,
+unless --private is given. Every request rereads your stacks, so changes made
+with the CLI show up right away, and changes made through the API are saved
+immediately. Both lock the data directory, so neither loses the other's.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		socket, _ := cmd.Flags().GetString("socket")
+		token, _ := cmd.Flags().GetString("token")
+		private, _ := cmd.Flags().GetBool("private")
+
+		tokenNote := "--token"
+		if token == "" {
+			path, err := serveToken()
+			if err != nil {
+				return err
+			}
+			if token, err = readToken(path); err != nil {
+				return err
+			}
+			tokenNote = path
+		}
+
+		var listener net.Listener
+		var err error
+		if socket != "" {
+			listener, err = listenSocket(socket)
+		} else {
+			listener, err = net.Listen("tcp", addr)
+			if err == nil && !isLoopback(addr) {
+				fmt.Fprintln(os.Stderr, warningStyle.Render("⚠ "+addr+" is reachable from other machines; anyone with the token can read and change your stacks"))
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+
+		srv := &http.Server{
+			Handler:           server.New(server.Options{Token: token, Private: private}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdown)
+		}()
+
+		where := "http://" + listener.Addr().String()
+		if socket != "" {
+			where = socket
+		}
+		fmt.Println(successStyle.Render("✔ Serving cam on " + where))
+		fmt.Println(explanationStyle.Render("Token: " + tokenNote + ". Press Ctrl+C to stop."))
+
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	},
+}
+
+// serveToken returns the path of the API token, generating one if needed.
+func serveToken() (string, error) {
+	dir, err := data.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, tokenFile)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(buf)+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write token: %w", err)
+	}
+	return path, nil
+}
+
+func readToken(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("%s is empty; delete it to generate a new token", path)
+	}
+	return token, nil
+}
+
+// listenSocket listens on a Unix socket only the current user can connect
+// to, replacing a stale socket left by a previous run.
+func listenSocket(path string) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another server", path)
+		}
+		os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func init() {
+	serveCmd.Flags().String("addr", "127.0.0.1:7373", "address to listen on")
+	serveCmd.Flags().String("socket", "", "listen on this Unix socket instead of --addr")
+	serveCmd.Flags().String("token", "", "token clients must send (default: the one in serve_token)")
+	serveCmd.Flags().Bool("private", false, "include the text of private commands")
+	rootCmd.AddCommand(serveCmd)
+}
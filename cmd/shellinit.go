@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"cam/internal/data"
+	"cam/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+const bashHook = `__cam_record() {
+  local code=$?
+  local cmd
+  cmd=$(fc -ln -1 2>/dev/null)
+  cmd="${cmd#"${cmd%%[![:space:]]*}"}"
+  case "$cmd" in
+    "cam fix"*|"") ;;
+    "cam wrap "*) printf '%s\n%s\n' "$code" "$cmd" > "{{RECORD}}" ;;
+    *) printf '%s\n%s\n' "$code" "$cmd" > "{{RECORD}}"; rm -f "{{STDERR}}" ;;
+  esac
+  return $code
+}
+PROMPT_COMMAND="__cam_record${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+`
+
+const zshHook = `__cam_preexec() { __cam_last_cmd="$1"; }
+__cam_precmd() {
+  local code=$?
+  case "$__cam_last_cmd" in
+    "cam fix"*|"") ;;
+    "cam wrap "*) printf '%s\n%s\n' "$code" "$__cam_last_cmd" > "{{RECORD}}" ;;
+    *) printf '%s\n%s\n' "$code" "$__cam_last_cmd" > "{{RECORD}}"; rm -f "{{STDERR}}" ;;
+  esac
+  __cam_last_cmd=""
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec __cam_preexec
+add-zsh-hook precmd __cam_precmd
+`
+
+const fishHook = `function __cam_record --on-event fish_postexec
+    set -l code $status
+    switch "$argv"
+        case "cam fix*" ""
+        case "cam wrap *"
+            printf '%s\n%s\n' $code "$argv" > "{{RECORD}}"
+        case "*"
+            printf '%s\n%s\n' $code "$argv" > "{{RECORD}}"
+            rm -f "{{STDERR}}"
+    end
+end
+`
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init <bash|zsh|fish>",
+	Short: "Print shell integration hooks",
+	Long: `Print shell hooks that record the last command and its exit code,
+which 'cam fix' uses to repair failed commands.
+
+Add one of these to your shell's startup file:
+  eval "$(cam shell-init bash)"      # ~/.bashrc
+  eval "$(cam shell-init zsh)"       # ~/.zshrc
+  cam shell-init fish | source       # ~/.config/fish/config.fish
+
+To also capture a command's error output, run it through 'cam wrap -- <command>'.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := data.DataRoot()
+		if err != nil {
+			return err
+		}
+		record := filepath.Join(dir, history.RecordFile)
+
+		var hook string
+		switch args[0] {
+		case "bash":
+			hook = bashHook
+		case "zsh":
+			hook = zshHook
+		case "fish":
+			hook = fishHook
+		default:
+			return fmt.Errorf("unsupported shell '%s' (supported: bash, zsh, fish)", args[0])
+		}
+
+		fmt.Printf("mkdir -p %q\n", dir)
+		// Any command not run through 'cam wrap' drops the last capture, so
+		// it isn't attached to a later command with the same text.
+		stderr := filepath.Join(dir, history.StderrFile)
+		fmt.Print(strings.NewReplacer("{{RECORD}}", record, "{{STDERR}}", stderr).Replace(hook))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"cam/internal/data"
+	"cam/internal/sources"
+
+	"github.com/spf13/cobra"
+)
+
+var sourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "Subscribe to stacks published by others",
+	Long: `Sources are stacks published elsewhere, e.g. a platform team's runbooks.
+Their stacks show up as <source>/<stack> in ls, f, search, run and cp, but
+can't be changed locally. 'cam sources update' fetches the latest version.
+
+A source can be:
+  a directory   with .cam.json and/or .cam/<stack>.json files, read in place
+  a git repo    (URL or bare repository) with the same layout, cloned
+  a bundle      written by 'cam export'
+
+A directory or repo may publish a bundle as cam-bundle.json instead, so its
+stacks can be signed ('cam export --sign'). Running commands from sources
+that aren't signed by a trusted signer ('cam keys trust') asks for
+confirmation first.
+
+Examples:
+  cam sources add team git@github.com:acme/runbooks.git
+  cam sources add ops /mnt/share/ops-stacks.json
+  cam run team/deploy 0`,
+}
+
+var sourcesAddCmd = &cobra.Command{
+	Use:   "add <name> <location>",
+	Short: "Subscribe to a source and fetch it",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, location := args[0], sources.Location(args[1])
+		kind, _ := cmd.Flags().GetString("kind")
+		if err := data.ValidateSourceName(name); err != nil {
+			return err
+		}
+		if kind == "" {
+			kind = sources.DetectKind(location)
+		} else if !slices.Contains([]string{data.SourceDir, data.SourceGit, data.SourceBundle}, kind) {
+			return fmt.Errorf("invalid kind '%s' (use dir, git or bundle)", kind)
+		}
+
+		list, err := data.StackSources()
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(list, func(s data.StackSource) bool { return s.Name == name }) {
+			return fmt.Errorf("source '%s' already exists", name)
+		}
+
+		src := data.StackSource{Name: name, Kind: kind, Location: location}
+		count, err := sources.Update(&src)
+		if err != nil {
+			data.RemoveSourceCache(name)
+			return err
+		}
+		if err := data.SaveStackSources(append(list, src)); err != nil {
+			return err
+		}
+
+		fmt.Println(successStyle.Render(fmt.Sprintf("✔ Added %s source '%s' with %d stacks", kind, name, count)))
+		printSourceSigner(src)
+		fmt.Printf("List them with 'cam ls'; they are named %s/<stack>.\n", name)
+		return nil
+	},
+}
+
+var sourcesLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List sources",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := data.StackSources()
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			fmt.Println("No sources. Add one with 'cam sources add <name> <location>'.")
+			return nil
+		}
+
+		for _, src := range list {
+			updated := "never"
+			if t, err := time.Parse(time.RFC3339, src.Updated); err == nil {
+				updated = t.Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%s (%s) %s\n", src.Name, src.Kind, src.Location)
+			label, _ := signerLabel(src.Signer)
+			fmt.Println(explanationStyle.Render("  updated " + updated + ", " + label))
+		}
+		return nil
+	},
+}
+
+var sourcesRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Unsubscribe from a source",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		list, err := data.StackSources()
+		if err != nil {
+			return err
+		}
+
+		i := slices.IndexFunc(list, func(s data.StackSource) bool { return s.Name == name })
+		if i < 0 {
+			return fmt.Errorf("source '%s' not found", name)
+		}
+		if err := data.SaveStackSources(slices.Delete(list, i, i+1)); err != nil {
+			return err
+		}
+		if err := data.RemoveSourceCache(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove the cached copy: %v\n", err)
+		}
+
+		fmt.Printf("Removed source '%s'\n", name)
+		return nil
+	},
+}
+
+var sourcesUpdateCmd = &cobra.Command{
+	Use:   "update [name...]",
+	Short: "Fetch the latest stacks of all or some sources",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := data.StackSources()
+		if err != nil {
+			return err
+		}
+		for _, name := range args {
+			if !slices.ContainsFunc(list, func(s data.StackSource) bool { return s.Name == name }) {
+				return fmt.Errorf("source '%s' not found", name)
+			}
+		}
+
+		failed := 0
+		for i := range list {
+			src := &list[i]
+			if len(args) > 0 && !slices.Contains(args, src.Name) {
+				continue
+			}
+			count, err := sources.Update(src)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				failed++
+				continue
+			}
+			fmt.Printf("%s: %d stacks\n", src.Name, count)
+			printSourceSigner(*src)
+		}
+
+		if err := data.SaveStackSources(list); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d sources could not be updated; their previous stacks are kept", failed)
+		}
+		return nil
+	},
+}
+
+// printSourceSigner warns when running the source's commands will need
+// confirmation because it isn't signed by a trusted signer.
+func printSourceSigner(src data.StackSource) {
+	if label, trusted := signerLabel(src.Signer); !trusted {
+		fmt.Fprintln(os.Stderr, warningStyle.Render(fmt.Sprintf("⚠ '%s' is %s; running its commands asks for confirmation", src.Name, label)))
+	}
+}
+
+func init() {
+	sourcesAddCmd.Flags().String("kind", "", "dir, git or bundle (default: detected from the location)")
+	sourcesCmd.AddCommand(sourcesAddCmd, sourcesLsCmd, sourcesRmCmd, sourcesUpdateCmd)
+	rootCmd.AddCommand(sourcesCmd)
+}
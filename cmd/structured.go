@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"cam/internal/ai"
+	"cam/internal/data"
+	"cam/internal/prompts"
+	"cam/internal/safety"
+	"cam/internal/sysinfo"
+)
+
+// commandResult is a generated command with the model's notes about it.
+// Only Command is set when the backend answered in free-form text.
+type commandResult struct {
+	Command     string   `json:"command"`
+	Explanation string   `json:"explanation"`
+	Risk        string   `json:"risk"`
+	Requires    []string `json:"requires"`
+	// IsPrivate marks a saved private command, so pinning it keeps it encrypted.
+	IsPrivate bool `json:"-"`
+}
+
+// commandSchema constrains cmdr and fix answers to a commandResult.
+var commandSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"command":     map[string]any{"type": "string"},
+		"explanation": map[string]any{"type": "string"},
+		"risk":        map[string]any{"type": "string", "enum": []string{"low", "medium", "high"}},
+		"requires":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	},
+	"required": []string{"command", "explanation", "risk", "requires"},
+}
+
+// generateStructured renders the named prompt and asks for a schema-constrained
+// JSON answer. If the backend can't do structured output, or its answer doesn't
+// match the schema, it falls back to free-form text and the parsing heuristics.
+func generateStructured(ctx context.Context, configStore *data.ConfigStore, name string, vars prompts.Vars) (commandResult, error) {
+	vars.Format = "json"
+	prompt, err := prompts.Render(name, vars)
+	if err != nil {
+		return commandResult{}, err
+	}
+
+	resultText, err := ai.GenerateJSON(ctx, configStore, prompt, commandSchema)
+	if err != nil && !errors.Is(err, ai.ErrStructuredUnsupported) {
+		return commandResult{}, err
+	}
+	if err == nil {
+		result, parseErr := parseStructuredCommand(resultText)
+		if parseErr == nil {
+			return result, nil
+		}
+		fmt.Fprintf(os.Stderr, "Warning: ignoring malformed structured answer (%v)\n", parseErr)
+	}
+
+	vars.Format = ""
+	prompt, err = prompts.Render(name, vars)
+	if err != nil {
+		return commandResult{}, err
+	}
+
+	resultText, err = ai.GenerateContent(ctx, configStore, prompt)
+	if err != nil {
+		return commandResult{}, err
+	}
+
+	command, err := parseCommandResponse(resultText)
+	if err != nil {
+		return commandResult{}, err
+	}
+	return commandResult{Command: command}, nil
+}
+
+// parseStructuredCommand strictly decodes a JSON answer: unknown fields,
+// trailing data, an empty command or an unknown risk level are errors.
+func parseStructuredCommand(resultText string) (commandResult, error) {
+	dec := json.NewDecoder(strings.NewReader(resultText))
+	dec.DisallowUnknownFields()
+
+	var result commandResult
+	if err := dec.Decode(&result); err != nil {
+		return commandResult{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return commandResult{}, fmt.Errorf("unexpected data after JSON object")
+	}
+
+	result.Command = strings.TrimSpace(result.Command)
+	if result.Command == "" {
+		return commandResult{}, fmt.Errorf("empty command")
+	}
+	if !slices.Contains([]string{"low", "medium", "high"}, result.Risk) {
+		return commandResult{}, fmt.Errorf("unknown risk level %q", result.Risk)
+	}
+	return result, nil
+}
+
+// missing lists the executables the command needs that aren't installed,
+// combining the parsed command with what the model said it requires.
+func (r commandResult) missing() []string {
+	missing := missingExecutables(r.Command)
+	var required []string
+	for _, name := range r.Requires {
+		if name != "" && !safety.IsBuiltin(name) && !slices.Contains(missing, name) {
+			required = append(required, name)
+		}
+	}
+	return append(missing, sysinfo.Missing(required)...)
+}
+
+// showCommand prints a command followed by its notes.
+func showCommand(r commandResult) {
+	fmt.Println(commandStyle.Render(r.Command))
+	printNotes(r)
+}
+
+// printNotes prints the model's explanation and any risks found by the safety
+// check or, failing that, flagged by the model.
+func printNotes(r commandResult) {
+	if r.Explanation != "" {
+		fmt.Println(explanationStyle.Render(r.Explanation))
+	}
+	if !printRisks(r.Command) && (r.Risk == "medium" || r.Risk == "high") {
+		fmt.Fprintln(os.Stderr, warningStyle.Render(fmt.Sprintf("⚠ the model rates this command %s risk", r.Risk)))
+	}
+}
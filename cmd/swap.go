@@ -35,19 +35,9 @@ Example:
 		}
 
 		store := data.NewDataStore()
-		if err := store.LoadData(false); err != nil {
-			return fmt.Errorf("failed to load data store: %w", err)
-		}
-
-		if err := store.Swap(stackName, index1, index2); err != nil {
-			return err
-		}
-
-		if err := store.SaveData(); err != nil {
-			return fmt.Errorf("failed to save data store: %w", err)
-		}
-
-		return nil
+		return store.Update(func() error {
+			return store.Swap(stackName, index1, index2)
+		})
 	},
 }
 
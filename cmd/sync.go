@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"cam/internal/data"
+	"cam/internal/gitsync"
+
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync stacks with a git remote",
+	Long: `Keep your stacks in a git repository and sync them between machines.
+
+After 'cam sync init <remote>', every change is committed locally and
+'cam sync' pulls, merges and pushes. The remote can be any URL or path your
+git can reach (including a bare repository on a shared drive).
+
+Concurrent edits are merged stack by stack: commands added on both machines
+are kept, deletions and description/tag edits are applied, so the JSON never
+conflicts. Only data.json is tracked. Private commands stay encrypted; copy
+the .keys directory to other machines to read them there.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := openSyncRepo()
+		if err != nil {
+			return err
+		}
+
+		res, err := syncLocked(repo)
+		if err != nil {
+			return fmt.Errorf("sync failed: %w", err)
+		}
+
+		switch {
+		case res.Merged:
+			fmt.Printf("Merged %d remote changes with yours.\n", res.Pulled)
+		case res.Pulled > 0:
+			fmt.Printf("Pulled %d changes.\n", res.Pulled)
+		}
+		if res.Pushed > 0 {
+			fmt.Printf("Pushed %d changes.\n", res.Pushed)
+		}
+		fmt.Println(successStyle.Render("✔ Stacks are in sync"))
+		return nil
+	},
+}
+
+var syncInitCmd = &cobra.Command{
+	Use:   "init [remote]",
+	Short: "Start tracking stacks in git, optionally with a remote",
+	Long: `Turn the data directory into a git repository and, if a remote is given,
+sync with it right away. Run it again to change the remote.
+
+  cam sync init git@github.com:me/cam-stacks.git
+  cam sync init /mnt/share/stacks.git`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := data.DataDir()
+		if err != nil {
+			return err
+		}
+		remote := ""
+		if len(args) == 1 {
+			remote = args[0]
+		}
+
+		repo, err := gitsync.Init(dir, remote)
+		if err != nil {
+			return fmt.Errorf("failed to set up sync: %w", err)
+		}
+		fmt.Printf("Tracking stacks in %s\n", dir)
+		if remote == "" {
+			fmt.Println("Add a remote with 'cam sync init <remote>' to sync with other machines.")
+			return nil
+		}
+		if _, err := syncLocked(repo); err != nil {
+			return fmt.Errorf("first sync failed: %w", err)
+		}
+		fmt.Println(successStyle.Render("✔ Synced with " + repo.RemoteURL()))
+		return nil
+	},
+}
+
+var syncStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the sync remote and pending changes",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := openSyncRepo()
+		if err != nil {
+			return err
+		}
+		st, err := repo.Status()
+		if err != nil {
+			return err
+		}
+
+		remote := st.Remote
+		if remote == "" {
+			remote = "(none)"
+		}
+		fmt.Printf("Repository:  %s\n", repo.Dir)
+		fmt.Printf("Remote:      %s\n", remote)
+		if st.LastCommit != "" {
+			fmt.Printf("Last change: %s\n", st.LastCommit)
+		}
+		fmt.Printf("Unpushed:    %d\n", st.Ahead)
+		fmt.Printf("Unmerged:    %d (as of the last sync)\n", st.Behind)
+		return nil
+	},
+}
+
+func openSyncRepo() (*gitsync.Repo, error) {
+	dir, err := data.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	return gitsync.Open(dir)
+}
+
+// commitOnSave records every change to a synced data directory.
+func commitOnSave(path string) {
+	dir := filepath.Dir(path)
+	if !gitsync.Enabled(dir) {
+		return
+	}
+	repo := &gitsync.Repo{Dir: dir}
+	if _, err := repo.Commit("Update stacks (" + currentCommand + ")"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to commit change for sync: %v\n", err)
+	}
+}
+
+// syncLocked syncs while holding the data lock: merging rewrites data.json,
+// so other cam processes must not save it meanwhile.
+func syncLocked(repo *gitsync.Repo) (gitsync.Result, error) {
+	unlock, err := data.Lock()
+	if err != nil {
+		return gitsync.Result{}, err
+	}
+	defer unlock()
+	return repo.Sync()
+}
+
+func init() {
+	data.OnSave(commitOnSave)
+	syncCmd.AddCommand(syncInitCmd)
+	syncCmd.AddCommand(syncStatusCmd)
+	rootCmd.AddCommand(syncCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"cam/internal/data"
+	"cam/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+// maxCapturedStderr bounds how much error output is kept for 'cam fix'.
+const maxCapturedStderr = 16 * 1024
+
+// tailBuffer keeps only the last limit bytes written to it.
+type tailBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf.Write(p)
+	if over := t.buf.Len() - t.limit; over > 0 {
+		t.buf.Next(over)
+	}
+	return len(p), nil
+}
+
+var wrapCmd = &cobra.Command{
+	Use:   "wrap -- <command>",
+	Short: "Run a command and capture its error output for 'cam fix'",
+	Long: `Run a command in the same shell as 'cam run' (the 'shell' setting, or
+$SHELL) while recording its exit code and
+error output, so 'cam fix' can send both to the model if it fails.
+The command's own exit code is preserved.
+
+Example:
+  cam wrap -- make build`,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 && args[0] == "--" {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("no command given")
+		}
+		cmdStr := strings.Join(args, " ")
+
+		stderr := &tailBuffer{limit: maxCapturedStderr}
+		execCmd := exec.Command(commandShell(), "-c", cmdStr)
+		execCmd.Stdin = os.Stdin
+		execCmd.Stdout = os.Stdout
+		execCmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+
+		runErr := execCmd.Run()
+		code := 0
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			code = exitErr.ExitCode()
+		} else if runErr != nil {
+			return fmt.Errorf("failed to run command: %w", runErr)
+		}
+
+		if dir, err := data.DataRoot(); err == nil {
+			rec := history.Record{Command: cmdStr, ExitCode: code, Stderr: stderr.buf.String()}
+			if err := history.WriteRecord(dir, rec); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+
+		if code != 0 {
+			os.Exit(code)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(wrapCmd)
+}
@@ -9,10 +9,49 @@ import (
 	"strings"
 )
 
-// GenerateContent abstracts the AI provider to return text from a prompt
+const provider = "ollama"
+
+type taskKey struct{}
+
+// WithTask returns a context whose generations use the model configured for
+// task (data.TaskAsk or data.TaskCmdr) instead of the default model.
+func WithTask(ctx context.Context, task string) context.Context {
+	return context.WithValue(ctx, taskKey{}, task)
+}
+
+// modelFor returns the model to use for the task stored in ctx.
+func modelFor(ctx context.Context, configStore *data.ConfigStore) string {
+	if task, ok := ctx.Value(taskKey{}).(string); ok {
+		return configStore.GetTaskModel(task)
+	}
+	return configStore.GetOllamaModel()
+}
+
+// GenerateContent abstracts the AI provider to return text from a prompt.
+// Responses are cached by provider, model and prompt unless the context
+// was created with WithoutCache or caching is disabled in the config.
 func GenerateContent(ctx context.Context, configStore *data.ConfigStore, prompt string) (string, error) {
-	model := configStore.GetOllamaModel()
-	return generateOllamaRun(ctx, model, prompt)
+	model := modelFor(ctx, configStore)
+
+	ttl := configStore.GetCacheTTL()
+	useCache := ttl > 0 && !cacheDisabled(ctx)
+	if useCache {
+		if cached, ok := cacheGet(provider, model, prompt, ttl); ok {
+			return cached, nil
+		}
+	}
+
+	runCtx, cancel := withTimeout(ctx, configStore)
+	defer cancel()
+	response, err := generateOllamaRun(runCtx, model, prompt)
+	if err != nil {
+		return "", timeoutError(runCtx, configStore, err)
+	}
+
+	if useCache {
+		cachePut(provider, model, prompt, response, ttl, configStore.GetCacheMaxBytes())
+	}
+	return response, nil
 }
 
 func generateOllamaRun(ctx context.Context, model string, prompt string) (string, error) {
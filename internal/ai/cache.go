@@ -0,0 +1,218 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cam/internal/data"
+)
+
+const statsFile = "stats.json"
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context whose generations skip the response cache,
+// e.g. for --no-cache or when the user explicitly asks to regenerate.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func cacheDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noCacheKey{}).(bool)
+	return disabled
+}
+
+type cacheEntry struct {
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Created  time.Time `json:"created"`
+	Response string    `json:"response"`
+}
+
+// CacheStats summarises the response cache.
+type CacheStats struct {
+	Dir     string
+	Entries int
+	Expired int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+	Hits    int `json:"hits"`
+	Misses  int `json:"misses"`
+}
+
+// CacheDir returns the directory holding cached responses.
+func CacheDir() (string, error) {
+	dir, err := data.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache", "responses"), nil
+}
+
+func cacheKey(provider, model, prompt string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + model + "\x00" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheGet returns a cached response that is younger than ttl.
+func cacheGet(provider, model, prompt string, ttl time.Duration) (string, bool) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", false
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, cacheKey(provider, model, prompt)+".json"))
+	if err != nil {
+		recordLookup(dir, false)
+		return "", false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil || time.Since(entry.Created) > ttl {
+		recordLookup(dir, false)
+		return "", false
+	}
+
+	recordLookup(dir, true)
+	return entry.Response, true
+}
+
+// cachePut stores a response and trims the cache to maxBytes. Failures are
+// only reported, since the response itself is still usable.
+func cachePut(provider, model, prompt, response string, ttl time.Duration, maxBytes int64) {
+	dir, err := CacheDir()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create cache directory: %v\n", err)
+		return
+	}
+
+	entry := cacheEntry{Provider: provider, Model: model, Created: time.Now(), Response: response}
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, cacheKey(provider, model, prompt)+".json"), content, 0600); err != nil { // Prompts can hold context the user wouldn't share.
+		fmt.Fprintf(os.Stderr, "Warning: failed to write response cache: %v\n", err)
+		return
+	}
+
+	pruneCache(dir, ttl, maxBytes)
+}
+
+// pruneCache removes expired entries, then the oldest ones until the cache fits in maxBytes.
+func pruneCache(dir string, ttl time.Duration, maxBytes int64) {
+	files := cacheFiles(dir)
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+
+	var total int64
+	for _, f := range files {
+		total += f.Size()
+	}
+
+	for _, f := range files {
+		if time.Since(f.ModTime()) <= ttl && total <= maxBytes {
+			continue
+		}
+		if os.Remove(filepath.Join(dir, f.Name())) == nil {
+			total -= f.Size()
+		}
+	}
+}
+
+func cacheFiles(dir string) []os.FileInfo {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var files []os.FileInfo
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == statsFile || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			files = append(files, info)
+		}
+	}
+	return files
+}
+
+func readStats(dir string) CacheStats {
+	var stats CacheStats
+	if content, err := os.ReadFile(filepath.Join(dir, statsFile)); err == nil {
+		_ = json.Unmarshal(content, &stats)
+	}
+	return stats
+}
+
+func recordLookup(dir string, hit bool) {
+	stats := readStats(dir)
+	if hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+
+	content, err := json.Marshal(struct {
+		Hits   int `json:"hits"`
+		Misses int `json:"misses"`
+	}{stats.Hits, stats.Misses})
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(dir, 0700) == nil {
+		_ = os.WriteFile(filepath.Join(dir, statsFile), content, 0600)
+	}
+}
+
+// Stats reports the size and hit rate of the response cache.
+func Stats(configStore *data.ConfigStore) (CacheStats, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return CacheStats{}, err
+	}
+
+	stats := readStats(dir)
+	stats.Dir = dir
+	ttl := configStore.GetCacheTTL()
+	for _, f := range cacheFiles(dir) {
+		stats.Entries++
+		stats.Bytes += f.Size()
+		if time.Since(f.ModTime()) > ttl {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || f.ModTime().Before(stats.Oldest) {
+			stats.Oldest = f.ModTime()
+		}
+		if f.ModTime().After(stats.Newest) {
+			stats.Newest = f.ModTime()
+		}
+	}
+	return stats, nil
+}
+
+// ClearCache removes every cached response and resets the statistics.
+func ClearCache() (int, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := len(cacheFiles(dir))
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return removed, nil
+}
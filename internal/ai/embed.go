@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"cam/internal/data"
+)
+
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+// Embed returns one embedding vector per input text using the configured embedding model.
+func Embed(ctx context.Context, configStore *data.ConfigStore, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var resp embedResponse
+	req := embedRequest{Model: configStore.GetEmbedModel(), Input: texts}
+	runCtx, cancel := withTimeout(ctx, configStore)
+	defer cancel()
+	if err := ollamaPost(runCtx, "/api/embed", req, &resp); err != nil {
+		return nil, timeoutError(runCtx, configStore, err)
+	}
+
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d inputs", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, nil
+}
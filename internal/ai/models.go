@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Model is a model installed in the local Ollama instance.
+type Model struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Details    struct {
+		Family            string `json:"family"`
+		ParameterSize     string `json:"parameter_size"`
+		QuantizationLevel string `json:"quantization_level"`
+	} `json:"details"`
+}
+
+// PullProgress is one status update streamed while pulling a model.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ListModels returns the models installed locally.
+func ListModels(ctx context.Context) ([]Model, error) {
+	var tags struct {
+		Models []Model `json:"models"`
+	}
+	if err := ollamaGet(ctx, "/api/tags", &tags); err != nil {
+		return nil, err
+	}
+	return tags.Models, nil
+}
+
+// HasModel reports whether name is installed. A name without a tag matches
+// the ":latest" tag, like the ollama CLI.
+func HasModel(models []Model, name string) bool {
+	want := normalizeModel(name)
+	for _, m := range models {
+		if normalizeModel(m.Name) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeModel(name string) string {
+	if !strings.Contains(name, ":") {
+		name += ":latest"
+	}
+	return name
+}
+
+// PullModel downloads a model, calling progress for every status update.
+func PullModel(ctx context.Context, name string, progress func(PullProgress)) error {
+	body, err := json.Marshal(map[string]any{"model": name, "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ollamaHost()+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama is not reachable at %s: %w", ollamaHost(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("ollama /api/pull failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var p PullProgress
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			continue
+		}
+		if p.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", name, p.Error)
+		}
+		if progress != nil {
+			progress(p)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read pull progress: %w", err)
+	}
+	return nil
+}
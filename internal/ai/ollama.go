@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"cam/internal/data"
+)
+
+const defaultOllamaHost = "http://127.0.0.1:11434"
+
+// ollamaHost returns the Ollama API base URL, honouring OLLAMA_HOST like the ollama CLI.
+func ollamaHost() string {
+	host := os.Getenv("OLLAMA_HOST")
+	if host == "" {
+		return defaultOllamaHost
+	}
+	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+		host = "http://" + host
+	}
+	return strings.TrimRight(host, "/")
+}
+
+// withTimeout bounds an AI request by the configured timeout.
+func withTimeout(ctx context.Context, configStore *data.ConfigStore) (context.Context, context.CancelFunc) {
+	if timeout := configStore.GetTimeout(); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func() {}
+}
+
+// timeoutError explains err when ctx ran out of time, so users know which setting to raise.
+func timeoutError(ctx context.Context, configStore *data.ConfigStore, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("no answer from ollama within %s (raise it with 'cam config timeout'): %w", configStore.GetTimeout(), err)
+	}
+	return err
+}
+
+// ollamaPost sends a JSON request to the Ollama API and decodes the JSON response into out.
+func ollamaPost(ctx context.Context, path string, in any, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ollamaHost()+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama is not reachable at %s: %w", ollamaHost(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("ollama %s failed: %s: %s", path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode ollama response: %w", err)
+	}
+	return nil
+}
+
+// ollamaGet fetches an Ollama API path and decodes the JSON response into out.
+func ollamaGet(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ollamaHost()+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama is not reachable at %s: %w", ollamaHost(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("ollama %s failed: %s: %s", path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode ollama response: %w", err)
+	}
+	return nil
+}
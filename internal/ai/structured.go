@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"cam/internal/data"
+)
+
+// ErrStructuredUnsupported is returned by GenerateJSON when the backend can't
+// constrain output to a JSON schema, so callers can fall back to free-form text.
+var ErrStructuredUnsupported = errors.New("structured output is not supported by this backend")
+
+type generateRequest struct {
+	Model  string          `json:"model"`
+	Prompt string          `json:"prompt"`
+	Format json.RawMessage `json:"format"`
+	Stream bool            `json:"stream"`
+}
+
+type generateResponse struct {
+	Response string `json:"response"`
+}
+
+// GenerateJSON returns the model's answer to prompt constrained to the given
+// JSON schema (Ollama's "format" parameter). Responses are cached like
+// GenerateContent, keyed by the schema as well as the prompt.
+func GenerateJSON(ctx context.Context, configStore *data.ConfigStore, prompt string, schema any) (string, error) {
+	model := modelFor(ctx, configStore)
+
+	format, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode schema: %w", err)
+	}
+	key := prompt + "\x00" + string(format)
+
+	ttl := configStore.GetCacheTTL()
+	useCache := ttl > 0 && !cacheDisabled(ctx)
+	if useCache {
+		if cached, ok := cacheGet(provider, model, key, ttl); ok {
+			return cached, nil
+		}
+	}
+
+	runCtx, cancel := withTimeout(ctx, configStore)
+	defer cancel()
+	response, err := generateOllamaJSON(runCtx, model, prompt, format)
+	if err != nil {
+		return "", timeoutError(runCtx, configStore, err)
+	}
+
+	if useCache {
+		cachePut(provider, model, key, response, ttl, configStore.GetCacheMaxBytes())
+	}
+	return response, nil
+}
+
+func generateOllamaJSON(ctx context.Context, model string, prompt string, format json.RawMessage) (string, error) {
+	body, err := json.Marshal(generateRequest{Model: model, Prompt: prompt, Format: format})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ollamaHost()+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ollama is not reachable at %s: %w", ollamaHost(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		msg := strings.TrimSpace(string(raw))
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(raw, &apiErr) == nil && apiErr.Error != "" {
+			msg = apiErr.Error
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			return "", fmt.Errorf("model '%s' is not installed; run 'cam models pull %s' first", model, model)
+		case resp.StatusCode == http.StatusNotImplemented,
+			resp.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(msg), "format"):
+			// Older Ollama versions reject schema formats; proxies may not implement them.
+			return "", fmt.Errorf("%w: %s: %s", ErrStructuredUnsupported, resp.Status, msg)
+		default:
+			return "", fmt.Errorf("ollama /api/generate failed: %s: %s", resp.Status, msg)
+		}
+	}
+
+	var out generateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode ollama response: %w", err)
+	}
+	return strings.TrimSpace(out.Response), nil
+}
@@ -0,0 +1,102 @@
+package aicontext
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultTokenBudget is used when no budget is configured.
+const DefaultTokenBudget = 2000
+
+// Options carries the inputs shared by all providers.
+type Options struct {
+	Dir   string   // directory the context is gathered from
+	Files []string // files whose contents should be included
+}
+
+// Provider gathers one section of context for an AI prompt.
+type Provider struct {
+	Name        string
+	Description string
+	Collect     func(opts Options) (string, error)
+	// BestEffort providers are skipped when they fail (e.g. "git" outside a
+	// repository); errors from the others, such as a --file that can't be
+	// read, are returned.
+	BestEffort bool
+}
+
+var providers = map[string]Provider{}
+
+func register(p Provider) {
+	providers[p.Name] = p
+}
+
+// Providers returns every registered provider, sorted by name.
+func Providers() []Provider {
+	var list []Provider
+	for _, p := range providers {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Lookup returns the provider with the given name.
+func Lookup(name string) (Provider, bool) {
+	p, ok := providers[name]
+	return p, ok
+}
+
+// EstimateTokens approximates the token count of s (roughly 4 characters per token).
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// truncatedMarker ends a section that was cut to fit the budget.
+const truncatedMarker = "\n...[truncated]\n"
+
+// Build runs the named providers in order and joins their sections, truncating
+// so the result fits in budget tokens. Earlier providers get priority.
+func Build(names []string, opts Options, budget int) (string, error) {
+	if budget <= 0 {
+		budget = DefaultTokenBudget
+	}
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+
+	remaining := budget * 4 // characters
+	var sb strings.Builder
+	for _, name := range names {
+		p, ok := providers[name]
+		if !ok {
+			return "", fmt.Errorf("unknown context provider '%s'", name)
+		}
+		// Once the budget is spent only the providers that must not fail
+		// quietly (such as --file) are still run, to surface their errors.
+		if remaining <= len(truncatedMarker) && p.BestEffort {
+			continue
+		}
+
+		content, err := p.Collect(opts)
+		if err != nil {
+			if p.BestEffort {
+				continue
+			}
+			return "", fmt.Errorf("context provider '%s' failed: %w", name, err)
+		}
+		if remaining <= len(truncatedMarker) || strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		section := fmt.Sprintf("[%s]\n%s\n", p.Description, strings.TrimRight(content, "\n"))
+		if len(section) > remaining {
+			cut := remaining - len(truncatedMarker)
+			section = strings.ToValidUTF8(section[:cut], "") + truncatedMarker
+		}
+		remaining -= len(section)
+		sb.WriteString(section)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,46 @@
+package aicontext
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuildStaysWithinBudget(t *testing.T) {
+	calls := 0
+	register(Provider{Name: "test-big", Description: "Big", BestEffort: true, Collect: func(Options) (string, error) {
+		return strings.Repeat("x", 1000), nil
+	}})
+	register(Provider{Name: "test-late", Description: "Late", BestEffort: true, Collect: func(Options) (string, error) {
+		calls++
+		return "late", nil
+	}})
+	register(Provider{Name: "test-broken", Description: "Broken", Collect: func(Options) (string, error) {
+		return "", errors.New("unreadable")
+	}})
+	defer func() {
+		delete(providers, "test-big")
+		delete(providers, "test-late")
+		delete(providers, "test-broken")
+	}()
+
+	for _, budget := range []int{5, 10, 50} {
+		got, err := Build([]string{"test-big", "test-late"}, Options{}, budget)
+		if err != nil {
+			t.Fatalf("Build(%d): %v", budget, err)
+		}
+		if len(got) > budget*4 {
+			t.Errorf("Build(%d) returned %d characters, want at most %d", budget, len(got), budget*4)
+		}
+		if !strings.HasSuffix(got, truncatedMarker) {
+			t.Errorf("Build(%d) = %q, want the truncation marker", budget, got)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("provider collected %d times after the budget was spent", calls)
+	}
+
+	if _, err := Build([]string{"test-big", "test-broken"}, Options{}, 5); err == nil {
+		t.Error("Build succeeded, want the failing provider's error")
+	}
+}
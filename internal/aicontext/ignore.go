@@ -0,0 +1,130 @@
+package aicontext
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoredDirs are always skipped, regardless of ignore files.
+var ignoredDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	".git":         true,
+	".tea":         true,
+	"__pycache__":  true,
+}
+
+type ignoreRule struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// Ignore matches paths against .gitignore/.camignore style rules.
+type Ignore struct {
+	rules []ignoreRule
+}
+
+// LoadIgnore reads .gitignore and .camignore from root. Missing files are ignored.
+func LoadIgnore(root string) *Ignore {
+	ig := &Ignore{}
+	for _, name := range []string{".gitignore", ".camignore"} {
+		f, err := os.Open(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			ig.add(scanner.Text())
+		}
+		f.Close()
+	}
+	return ig
+}
+
+func (ig *Ignore) add(line string) {
+	line = strings.TrimRight(line, " ")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.HasPrefix(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	} else if strings.Contains(line, "/") {
+		// Patterns containing a slash are relative to the ignore file.
+		rule.anchored = true
+	}
+	rule.pattern = line
+	ig.rules = append(ig.rules, rule)
+}
+
+// Match reports whether rel (slash separated, relative to the root) should be skipped.
+func (ig *Ignore) Match(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(rel)
+	base := path.Base(rel)
+	if isDir && ignoredDirs[base] {
+		return true
+	}
+
+	ignored := false
+	for _, r := range ig.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		var ok bool
+		if r.anchored {
+			ok = matchGlob(r.pattern, rel)
+		} else {
+			ok, _ = path.Match(r.pattern, base)
+		}
+		if ok {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// matchGlob matches a slash separated pattern supporting "**" against name.
+func matchGlob(pattern, name string) bool {
+	patParts := strings.Split(pattern, "/")
+	nameParts := strings.Split(name, "/")
+
+	var match func(p, n []string) bool
+	match = func(p, n []string) bool {
+		for len(p) > 0 {
+			if p[0] == "**" {
+				for i := 0; i <= len(n); i++ {
+					if match(p[1:], n[i:]) {
+						return true
+					}
+				}
+				return false
+			}
+			if len(n) == 0 {
+				return false
+			}
+			if ok, _ := path.Match(p[0], n[0]); !ok {
+				return false
+			}
+			p, n = p[1:], n[1:]
+		}
+		return len(n) == 0
+	}
+	return match(patParts, nameParts)
+}
@@ -0,0 +1,213 @@
+package aicontext
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"cam/internal/data"
+	"cam/internal/history"
+	"cam/internal/sysinfo"
+)
+
+const (
+	treeDepth     = 2
+	flatDepth     = 3
+	readmeLines   = 60
+	historyLength = 20
+	gitLogLength  = 5
+)
+
+// versionedTools are reported by the "system" provider when installed.
+var versionedTools = []string{"git", "go", "node", "python3", "docker", "kubectl", "make"}
+
+func init() {
+	register(Provider{
+		Name:        "tree",
+		Description: "Current directory tree",
+		Collect: func(opts Options) (string, error) {
+			return FileTree(opts.Dir, treeDepth), nil
+		},
+	})
+
+	register(Provider{
+		Name:        "paths",
+		Description: "Directory structure (flat list)",
+		Collect: func(opts Options) (string, error) {
+			return FlatFileList(opts.Dir, flatDepth), nil
+		},
+	})
+
+	register(Provider{
+		Name:        "git",
+		Description: "Git branch, status and recent log",
+		Collect:     collectGit,
+		BestEffort:  true,
+	})
+
+	register(Provider{
+		Name:        "readme",
+		Description: "README excerpt",
+		Collect:     collectReadme,
+	})
+
+	register(Provider{
+		Name:        "file",
+		Description: "Selected file contents",
+		Collect:     collectFiles,
+	})
+
+	register(Provider{
+		Name:        "system",
+		Description: "OS, shell and tool versions",
+		Collect:     collectSystem,
+		BestEffort:  true,
+	})
+
+	register(Provider{
+		Name:        "history",
+		Description: "Recent shell history",
+		Collect:     collectHistory,
+		BestEffort:  true,
+	})
+
+	register(Provider{
+		Name:        "stacks",
+		Description: "Saved cam stacks (public)",
+		Collect:     collectStacks,
+		BestEffort:  true,
+	})
+}
+
+func runIn(dir string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return strings.TrimRight(string(out), "\n"), err
+}
+
+func collectGit(opts Options) (string, error) {
+	branch, err := runIn(opts.Dir, "git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err // not a repository
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("branch: %s\n", branch))
+
+	if status, err := runIn(opts.Dir, "git", "status", "--short"); err == nil {
+		if status == "" {
+			status = "(clean)"
+		}
+		sb.WriteString("status:\n" + status + "\n")
+	}
+	if log, err := runIn(opts.Dir, "git", "log", "--oneline", fmt.Sprintf("-%d", gitLogLength)); err == nil && log != "" {
+		sb.WriteString("recent commits:\n" + log + "\n")
+	}
+	return sb.String(), nil
+}
+
+func collectReadme(opts Options) (string, error) {
+	entries, err := os.ReadDir(opts.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(strings.ToLower(e.Name()), "readme") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(opts.Dir, e.Name()))
+		if err != nil {
+			return "", err
+		}
+		lines := strings.Split(string(content), "\n")
+		if len(lines) > readmeLines {
+			lines = lines[:readmeLines]
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	return "", nil
+}
+
+func collectFiles(opts Options) (string, error) {
+	var sb strings.Builder
+	for _, name := range opts.Files {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		sb.WriteString(fmt.Sprintf("--- %s ---\n%s\n", name, content))
+	}
+	return sb.String(), nil
+}
+
+func collectSystem(opts Options) (string, error) {
+	info := sysinfo.Detect()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("os: %s/%s\n", info.OS, runtime.GOARCH))
+	if info.Distro != "" {
+		sb.WriteString(fmt.Sprintf("distro: %s\n", info.Distro))
+	}
+	sb.WriteString(fmt.Sprintf("shell: %s\n", info.Shell))
+	if info.Coreutils != "" {
+		sb.WriteString(fmt.Sprintf("coreutils: %s\n", info.Coreutils))
+	}
+
+	for _, tool := range versionedTools {
+		if _, err := exec.LookPath(tool); err != nil {
+			continue
+		}
+		flag := "--version"
+		if tool == "go" {
+			flag = "version"
+		}
+		out, err := runIn(opts.Dir, tool, flag)
+		if err != nil {
+			continue
+		}
+		first, _, _ := strings.Cut(out, "\n")
+		sb.WriteString(fmt.Sprintf("%s: %s\n", tool, first))
+	}
+	return sb.String(), nil
+}
+
+func collectHistory(opts Options) (string, error) {
+	entries, err := history.Load(history.DetectShell())
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, e := range history.Last(entries, historyLength) {
+		sb.WriteString(e.Command + "\n")
+	}
+	return sb.String(), nil
+}
+
+func collectStacks(opts Options) (string, error) {
+	store := data.NewDataStore()
+	if err := store.LoadData(false); err != nil {
+		return "", err
+	}
+
+	var names []string
+	for name := range store.Stacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name + ":\n")
+		for i, c := range store.Stacks[name] {
+			sb.WriteString(fmt.Sprintf("  [%d] %s\n", i, c.Cmd))
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,94 @@
+package aicontext
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// visibleEntries lists dir, dropping hidden files and anything ignored.
+func visibleEntries(root, dir string, ig *Ignore) []os.DirEntry {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var validEntries []os.DirEntry
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue // skip hidden
+		}
+		rel, err := filepath.Rel(root, filepath.Join(dir, name))
+		if err != nil || ig.Match(rel, e.IsDir()) {
+			continue
+		}
+		validEntries = append(validEntries, e)
+	}
+	return validEntries
+}
+
+// FileTree renders dir as an indented tree up to maxDepth levels deep.
+func FileTree(dir string, maxDepth int) string {
+	ig := LoadIgnore(dir)
+	return ".\n" + buildFileTree(dir, dir, ig, "", 0, maxDepth)
+}
+
+func buildFileTree(root, dir string, ig *Ignore, prefix string, depth int, maxDepth int) string {
+	if depth > maxDepth {
+		return ""
+	}
+
+	validEntries := visibleEntries(root, dir, ig)
+
+	var sb strings.Builder
+	for i, e := range validEntries {
+		isLast := i == len(validEntries)-1
+		connector := "├── "
+		newPrefix := prefix + "│   "
+		if isLast {
+			connector = "└── "
+			newPrefix = prefix + "    "
+		}
+
+		name := e.Name()
+		if e.IsDir() {
+			name += "/"
+		}
+
+		sb.WriteString(fmt.Sprintf("%s%s%s\n", prefix, connector, name))
+
+		if e.IsDir() {
+			sb.WriteString(buildFileTree(root, filepath.Join(dir, e.Name()), ig, newPrefix, depth+1, maxDepth))
+		}
+	}
+	return sb.String()
+}
+
+// FlatFileList lists every visible path under root, one per line, up to maxDepth levels deep.
+func FlatFileList(root string, maxDepth int) string {
+	ig := LoadIgnore(root)
+
+	var sb strings.Builder
+
+	// Helper for recursion
+	var walk func(path string, depth int)
+	walk = func(path string, depth int) {
+		if depth > maxDepth {
+			return
+		}
+
+		for _, e := range visibleEntries(root, path, ig) {
+			fullPath := filepath.Join(path, e.Name())
+			sb.WriteString(fullPath + "\n")
+
+			if e.IsDir() {
+				walk(fullPath, depth+1)
+			}
+		}
+	}
+
+	walk(root, 1)
+	return sb.String()
+}
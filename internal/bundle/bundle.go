@@ -0,0 +1,416 @@
+// Package bundle implements cam's portable bundle: a versioned JSON or YAML
+// document holding stacks, for sharing them between machines and people.
+//
+// Version 1 looks like this (YAML uses the same keys):
+//
+//	{
+//	  "format": "cam-bundle",
+//	  "version": 1,
+//	  "created": "2026-10-19T08:00:00Z",
+//	  "recipient": "SHA256:3q2+7w...",
+//	  "stacks": [
+//	    {
+//	      "name": "git",
+//	      "commands": [
+//	        {"cmd": "git status -sb", "description": "Short status", "tags": ["vcs"]},
+//	        {"private": true, "encrypted": "bWFkZSB5b3UgbG9vaw=="}
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// Commands are listed in stack order. Private commands are only present
+// when the bundle was made for a recipient: they are RSA-OAEP encrypted for
+// the recipient's public key (base64) and "recipient" holds its fingerprint.
+// Readers reject bundles with a newer version than they support.
+//
+// A bundle may be signed ('cam export --sign'). "signature" then holds the
+// signer's Ed25519 public key and a signature over the bundle's compact JSON
+// encoding without the signature field, so JSON and YAML bundles verify the
+// same way:
+//
+//	"signature": {"signer": "ed25519:MCowBQ...", "value": "3uq1Zx..."}
+package bundle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"cam/internal/crypto"
+	"cam/internal/data"
+	"cam/internal/interop"
+)
+
+const (
+	FormatName = "cam-bundle"
+	Version    = 1
+)
+
+// ErrUnsigned is returned by Verify for bundles without a signature.
+var ErrUnsigned = errors.New("bundle is not signed")
+
+type Bundle struct {
+	Format    string     `json:"format"`
+	Version   int        `json:"version"`
+	Created   string     `json:"created,omitempty"`
+	Recipient string     `json:"recipient,omitempty"`
+	Stacks    []Stack    `json:"stacks"`
+	Signature *Signature `json:"signature,omitempty"`
+
+	recipientKey string
+}
+
+type Signature struct {
+	Signer string `json:"signer"`
+	Value  string `json:"value"`
+}
+
+type Stack struct {
+	Name     string    `json:"name"`
+	Commands []Command `json:"commands"`
+}
+
+type Command struct {
+	Cmd         string   `json:"cmd,omitempty"`
+	Encrypted   string   `json:"encrypted,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Private     bool     `json:"private,omitempty"`
+	Timestamp   string   `json:"timestamp,omitempty"`
+}
+
+// New starts an empty bundle. Private commands are encrypted for the public
+// key at recipientKey, or left out when it is empty.
+func New(recipientKey string) (*Bundle, error) {
+	b := &Bundle{
+		Format:       FormatName,
+		Version:      Version,
+		Created:      time.Now().UTC().Format(time.RFC3339),
+		recipientKey: recipientKey,
+	}
+	if recipientKey != "" {
+		fingerprint, err := crypto.Fingerprint(recipientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient key: %w", err)
+		}
+		b.Recipient = fingerprint
+	}
+	return b, nil
+}
+
+// AddStack appends a stack. Private commands must be decrypted; it returns
+// how many were left out because the bundle has no recipient.
+func (b *Bundle) AddStack(name string, commands []data.Command) (int, error) {
+	stack := Stack{Name: name, Commands: []Command{}}
+	skipped := 0
+	for _, c := range commands {
+		bc := Command{
+			Cmd:         c.Cmd,
+			Description: c.Description,
+			Tags:        c.Tags,
+			Timestamp:   c.Timestamp,
+		}
+		if c.IsPrivate {
+			if b.recipientKey == "" {
+				skipped++
+				continue
+			}
+			cipherBytes, err := crypto.Encrypt([]byte(c.Cmd), b.recipientKey)
+			if err != nil {
+				return skipped, fmt.Errorf("failed to encrypt a private command in '%s': %w", name, err)
+			}
+			bc.Cmd = ""
+			bc.Private = true
+			bc.Encrypted = base64.StdEncoding.EncodeToString(cipherBytes)
+		}
+		stack.Commands = append(stack.Commands, bc)
+	}
+	if len(stack.Commands) > 0 {
+		b.Stacks = append(b.Stacks, stack)
+	}
+	return skipped, nil
+}
+
+// Count returns the number of public and private commands in the bundle.
+func (b *Bundle) Count() (public int, private int) {
+	for _, s := range b.Stacks {
+		for _, c := range s.Commands {
+			if c.Private {
+				private++
+			} else {
+				public++
+			}
+		}
+	}
+	return public, private
+}
+
+// DataCommands converts a stack for the data store. Private commands are
+// decrypted with the key in keysDir, or dropped when keysDir is empty.
+func (s Stack) DataCommands(keysDir string) ([]data.Command, error) {
+	var commands []data.Command
+	for _, c := range s.Commands {
+		dc := data.Command{
+			Cmd:         c.Cmd,
+			Description: c.Description,
+			Tags:        c.Tags,
+			IsPrivate:   c.Private,
+			Timestamp:   c.Timestamp,
+		}
+		if dc.Tags == nil {
+			dc.Tags = []string{}
+		}
+		if dc.Timestamp == "" {
+			dc.Timestamp = time.Now().Format(time.RFC3339)
+		}
+		if c.Private {
+			if keysDir == "" {
+				continue
+			}
+			cipherBytes, err := base64.StdEncoding.DecodeString(c.Encrypted)
+			if err != nil {
+				return nil, fmt.Errorf("invalid encrypted command in '%s': %w", s.Name, err)
+			}
+			plain, err := crypto.Decrypt(cipherBytes, filepath.Join(keysDir, "private_key.pem"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt a private command in '%s': %w", s.Name, err)
+			}
+			dc.Cmd = string(plain)
+		}
+		if strings.TrimSpace(dc.Cmd) == "" {
+			continue
+		}
+		commands = append(commands, dc)
+	}
+	return commands, nil
+}
+
+// Sign signs the bundle with priv. Changing the bundle afterwards
+// invalidates the signature.
+func (b *Bundle) Sign(priv ed25519.PrivateKey) error {
+	payload, err := b.payload()
+	if err != nil {
+		return err
+	}
+	b.Signature = &Signature{
+		Signer: crypto.FormatPublicKey(priv.Public().(ed25519.PublicKey)),
+		Value:  crypto.Sign(priv, payload),
+	}
+	return nil
+}
+
+// Verify checks the bundle's signature and returns the signer's public key.
+// It returns ErrUnsigned when the bundle has no signature.
+func (b *Bundle) Verify() (string, error) {
+	if b.Signature == nil {
+		return "", ErrUnsigned
+	}
+	payload, err := b.payload()
+	if err != nil {
+		return "", err
+	}
+	if err := crypto.Verify(b.Signature.Signer, payload, b.Signature.Value); err != nil {
+		return "", fmt.Errorf("invalid bundle signature: %w", err)
+	}
+	return b.Signature.Signer, nil
+}
+
+// payload is what gets signed: the compact JSON of the bundle without its
+// signature, with empty lists written the same way whichever format the
+// bundle was read from.
+func (b *Bundle) payload() ([]byte, error) {
+	unsigned := *b
+	unsigned.Signature = nil
+	unsigned.Stacks = make([]Stack, len(b.Stacks))
+	for i, s := range b.Stacks {
+		if s.Commands == nil {
+			s.Commands = []Command{}
+		}
+		unsigned.Stacks[i] = s
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(unsigned); err != nil {
+		return nil, fmt.Errorf("failed to encode bundle for signing: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes the bundle as indented JSON, or as YAML when asYAML is set.
+func (b *Bundle) Encode(w io.Writer, asYAML bool) error {
+	if !asYAML {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(b); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "format: %s\n", FormatName)
+	fmt.Fprintf(&buf, "version: %d\n", b.Version)
+	if b.Created != "" {
+		fmt.Fprintf(&buf, "created: %s\n", interop.YAMLString(b.Created))
+	}
+	if b.Recipient != "" {
+		fmt.Fprintf(&buf, "recipient: %s\n", interop.YAMLString(b.Recipient))
+	}
+	buf.WriteString("stacks:\n")
+	for _, s := range b.Stacks {
+		fmt.Fprintf(&buf, "  - name: %s\n", interop.YAMLString(s.Name))
+		buf.WriteString("    commands:\n")
+		for _, c := range s.Commands {
+			prefix := "      - "
+			field := func(key, value string) {
+				fmt.Fprintf(&buf, "%s%s: %s\n", prefix, key, value)
+				prefix = "        "
+			}
+			if c.Private {
+				field("private", "true")
+				field("encrypted", c.Encrypted)
+			} else {
+				field("cmd", interop.YAMLBlock(c.Cmd, "          "))
+			}
+			if c.Description != "" {
+				field("description", interop.YAMLString(c.Description))
+			}
+			if len(c.Tags) > 0 {
+				var tags []string
+				for _, t := range c.Tags {
+					tags = append(tags, interop.YAMLString(t))
+				}
+				field("tags", "["+strings.Join(tags, ", ")+"]")
+			}
+			if c.Timestamp != "" {
+				field("timestamp", interop.YAMLString(c.Timestamp))
+			}
+		}
+	}
+	if b.Signature != nil {
+		buf.WriteString("signature:\n")
+		fmt.Fprintf(&buf, "  signer: %s\n", interop.YAMLString(b.Signature.Signer))
+		fmt.Fprintf(&buf, "  value: %s\n", interop.YAMLString(b.Signature.Value))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ReadFile reads a bundle from path, or from stdin when path is "-".
+func ReadFile(path string) (*Bundle, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	return Decode(content)
+}
+
+// Decode parses a JSON or YAML bundle and checks its format and version.
+func Decode(content []byte) (*Bundle, error) {
+	var b *Bundle
+	var err error
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		b = &Bundle{}
+		if err = json.Unmarshal(trimmed, b); err != nil {
+			return nil, fmt.Errorf("failed to parse bundle: %w", err)
+		}
+	} else if b, err = decodeYAML(string(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+
+	if b.Format != FormatName {
+		return nil, fmt.Errorf("not a cam bundle (expected \"format\": \"%s\")", FormatName)
+	}
+	if b.Version < 1 || b.Version > Version {
+		return nil, fmt.Errorf("bundle version %d is not supported (this cam reads up to version %d)", b.Version, Version)
+	}
+	for _, s := range b.Stacks {
+		if strings.TrimSpace(s.Name) == "" {
+			return nil, fmt.Errorf("bundle has a stack without a name")
+		}
+	}
+	return b, nil
+}
+
+func decodeYAML(content string) (*Bundle, error) {
+	docs, err := interop.ParseYAML(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("expected one YAML document, found %d", len(docs))
+	}
+	doc, ok := docs[0].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a mapping at the top level")
+	}
+
+	b := &Bundle{
+		Format:    str(doc, "format"),
+		Created:   str(doc, "created"),
+		Recipient: str(doc, "recipient"),
+	}
+	if b.Version, err = strconv.Atoi(str(doc, "version")); err != nil {
+		return nil, fmt.Errorf("invalid version '%s'", str(doc, "version"))
+	}
+
+	stacks, _ := doc["stacks"].([]any)
+	for _, item := range stacks {
+		sm, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected stacks to be mappings")
+		}
+		stack := Stack{Name: str(sm, "name")}
+		commands, _ := sm["commands"].([]any)
+		for _, ci := range commands {
+			cm, ok := ci.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("expected the commands of '%s' to be mappings", stack.Name)
+			}
+			c := Command{
+				Cmd:         strings.TrimRight(str(cm, "cmd"), "\n"),
+				Encrypted:   str(cm, "encrypted"),
+				Description: str(cm, "description"),
+				Private:     str(cm, "private") == "true",
+				Timestamp:   str(cm, "timestamp"),
+			}
+			tags, _ := cm["tags"].([]any)
+			for _, t := range tags {
+				if tag, ok := t.(string); ok {
+					c.Tags = append(c.Tags, tag)
+				}
+			}
+			stack.Commands = append(stack.Commands, c)
+		}
+		b.Stacks = append(b.Stacks, stack)
+	}
+
+	if sig, ok := doc["signature"].(map[string]any); ok {
+		b.Signature = &Signature{Signer: str(sig, "signer"), Value: str(sig, "value")}
+	}
+	return b, nil
+}
+
+func str(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
@@ -0,0 +1,96 @@
+package bundle
+
+import (
+	"fmt"
+	"strings"
+
+	"cam/internal/data"
+)
+
+// Strategy decides how imported commands combine with an existing stack.
+type Strategy string
+
+const (
+	// Skip adds the commands the stack doesn't have yet.
+	Skip Strategy = "skip"
+	// Append adds every command, even if the stack already has it.
+	Append Strategy = "append"
+	// Replace makes the stack exactly the imported commands.
+	Replace Strategy = "replace"
+)
+
+// Strategies lists the merge strategies, the default first.
+var Strategies = []Strategy{Skip, Append, Replace}
+
+func ParseStrategy(s string) (Strategy, error) {
+	for _, strategy := range Strategies {
+		if string(strategy) == s {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown strategy '%s' (use skip, append or replace)", s)
+}
+
+// Change describes what importing into one stack does.
+type Change struct {
+	Stack      string
+	New        bool           // the stack doesn't exist yet
+	Added      []data.Command // commands that weren't in the stack
+	Duplicates []data.Command // imported commands the stack already had
+	Removed    []data.Command // existing commands dropped by Replace
+	Result     []data.Command // the stack after the import
+}
+
+// Merge combines the existing commands of a stack with imported ones.
+// Existing commands keep their place and new ones go to the end.
+func Merge(stack string, existing, incoming []data.Command, strategy Strategy) Change {
+	change := Change{Stack: stack, New: existing == nil}
+
+	have := make(map[string]bool)
+	for _, c := range existing {
+		have[key(c)] = true
+	}
+
+	switch strategy {
+	case Append:
+		change.Added = incoming
+		change.Result = append(append([]data.Command{}, existing...), incoming...)
+	case Replace:
+		incomingKeys := make(map[string]bool)
+		for _, c := range incoming {
+			incomingKeys[key(c)] = true
+			if have[key(c)] {
+				change.Duplicates = append(change.Duplicates, c)
+			} else {
+				change.Added = append(change.Added, c)
+			}
+		}
+		for _, c := range existing {
+			if !incomingKeys[key(c)] {
+				change.Removed = append(change.Removed, c)
+			}
+		}
+		change.Result = incoming
+	default:
+		change.Result = append([]data.Command{}, existing...)
+		for _, c := range incoming {
+			if have[key(c)] {
+				change.Duplicates = append(change.Duplicates, c)
+				continue
+			}
+			have[key(c)] = true
+			change.Added = append(change.Added, c)
+			change.Result = append(change.Result, c)
+		}
+	}
+	return change
+}
+
+// Changed reports whether applying the change modifies the store.
+func (c Change) Changed() bool {
+	return len(c.Added) > 0 || len(c.Removed) > 0
+}
+
+func key(c data.Command) string {
+	return strings.TrimSpace(c.Cmd)
+}
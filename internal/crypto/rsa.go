@@ -5,6 +5,7 @@ import (
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"os"
@@ -117,3 +118,23 @@ func Decrypt(ciphertext []byte, privKeyPath string) ([]byte, error) {
 
 	return plaintext, nil
 }
+
+// Fingerprint identifies a public key: the SHA-256 of its DER encoding,
+// written like ssh's "SHA256:..." fingerprints.
+func Fingerprint(pubKeyPath string) (string, error) {
+	keyBytes, err := os.ReadFile(pubKeyPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return "", fmt.Errorf("failed to decode public key PEM")
+	}
+	if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	sum := sha256.Sum256(block.Bytes)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SigningKeyFile holds the Ed25519 key used to sign bundles.
+	SigningKeyFile = "signing_key.pem"
+
+	publicKeyPrefix = "ed25519:"
+)
+
+// GenerateSigningKey creates a signing key in baseDir. An existing key is
+// only replaced with force.
+func GenerateSigningKey(baseDir string, force bool) error {
+	path := filepath.Join(baseDir, SigningKeyFile)
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("a signing key already exists (%s); use --force to replace it", path)
+	}
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signing key: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create signing key file: %w", err)
+	}
+	defer f.Close()
+
+	if err := pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return nil
+}
+
+// LoadSigningKey reads the signing key from baseDir.
+func LoadSigningKey(baseDir string) (ed25519.PrivateKey, error) {
+	keyBytes, err := os.ReadFile(filepath.Join(baseDir, SigningKeyFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no signing key; create one with 'cam keys sign-init'")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode signing key PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key is not an Ed25519 key")
+	}
+	return priv, nil
+}
+
+// FormatPublicKey writes a signing public key as "ed25519:<base64>".
+func FormatPublicKey(pub ed25519.PublicKey) string {
+	return publicKeyPrefix + base64.StdEncoding.EncodeToString(pub)
+}
+
+// ParsePublicKey reads a key written by FormatPublicKey.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(s), publicKeyPrefix)
+	if !ok {
+		return nil, fmt.Errorf("not an Ed25519 public key (expected %s<base64>)", publicKeyPrefix)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key")
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// SigningFingerprint is a short identifier for a signing public key.
+func SigningFingerprint(pub string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(pub)))
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])[:16]
+}
+
+// Sign signs message and returns the base64 signature.
+func Sign(priv ed25519.PrivateKey, message []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, message))
+}
+
+// Verify checks a base64 signature made by the public key pub.
+func Verify(pub string, message []byte, signature string) error {
+	key, err := ParsePublicKey(pub)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(key, message, sig) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
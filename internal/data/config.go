@@ -5,49 +5,136 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
-type Config struct {
-	OllamaModel string `json:"ollama_model"` // e.g. "llama3", "qwen2.5:1.5b"
+// Tasks that can be given their own model.
+const (
+	TaskAsk   = "ask"
+	TaskCmdr  = "cmdr"
+	TaskEmbed = "embed"
+)
+
+// Sources of a configuration value, in order of precedence.
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// configPath overrides the config file location (the --config flag).
+var configPath string
+
+// SetConfigPath makes every ConfigStore use path instead of the profile's config.json.
+func SetConfigPath(path string) {
+	configPath = path
 }
 
+// ConfigStore holds the settings from config.json. Values are kept as strings
+// and typed through the key registry; CAM_* environment variables override them.
 type ConfigStore struct {
-	Config Config `json:"config"`
-	path   string
-	mu     sync.RWMutex
+	values  map[string]string
+	unknown map[string]any // keys this version doesn't know, preserved on save
+	path    string
+	err     error // why path couldn't be determined, reported by LoadConfig and SaveConfig
+	mu      sync.RWMutex
 }
 
 func NewConfigStore() *ConfigStore {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil
+	cs := &ConfigStore{
+		values:  make(map[string]string),
+		unknown: make(map[string]any),
+		path:    configPath,
 	}
-	path := filepath.Join(home, ".config", "cam", "config.json")
-	return &ConfigStore{
-		path: path,
+	if cs.path == "" {
+		dir, err := ConfigDir()
+		if err != nil {
+			cs.err = err
+			return cs
+		}
+		cs.path = filepath.Join(dir, "config.json")
 	}
+	return cs
+}
+
+// Path returns the config file location.
+func (cs *ConfigStore) Path() string {
+	return cs.path
 }
 
 func (cs *ConfigStore) LoadConfig() error {
+	if cs.err != nil {
+		return cs.err
+	}
+
 	cs.mu.Lock()
-	defer cs.mu.Unlock()
+
+	cs.values = make(map[string]string)
+	cs.unknown = make(map[string]any)
 
 	data, err := os.ReadFile(cs.path)
 	if os.IsNotExist(err) {
-		cs.Config = Config{}
+		cs.mu.Unlock()
 		return nil
 	}
 	if err != nil {
+		cs.mu.Unlock()
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	return json.Unmarshal(data, &cs.Config)
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		cs.mu.Unlock()
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	migrated := false
+	for _, k := range registry {
+		if v, ok := raw[k.Name]; ok {
+			cs.values[k.Name] = stringify(v)
+			delete(raw, k.Name)
+		}
+		if k.legacy == "" {
+			continue
+		}
+		if v, ok := raw[k.legacy]; ok {
+			// Fields from before the key registry are moved to their new names.
+			if _, exists := cs.values[k.Name]; !exists && stringify(v) != "" {
+				cs.values[k.Name] = stringify(v)
+			}
+			delete(raw, k.legacy)
+			migrated = true
+		}
+	}
+	cs.unknown = raw
+	cs.mu.Unlock()
+
+	if migrated {
+		if err := cs.SaveConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to migrate config file: %v\n", err)
+		}
+	}
+	return nil
 }
 
 func (cs *ConfigStore) SaveConfig() error {
+	if cs.err != nil {
+		return cs.err
+	}
+
 	cs.mu.RLock()
-	data, err := json.MarshalIndent(cs.Config, "", "  ")
+	out := make(map[string]any, len(cs.values)+len(cs.unknown))
+	for name, v := range cs.unknown {
+		out[name] = v
+	}
+	for name, v := range cs.values {
+		k, _ := LookupKey(name)
+		out[name] = typedValue(k, v)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
 	cs.mu.RUnlock()
 
 	if err != nil {
@@ -62,18 +149,225 @@ func (cs *ConfigStore) SaveConfig() error {
 	return os.WriteFile(cs.path, data, 0644)
 }
 
-func (cs *ConfigStore) SetOllamaModel(model string) error {
+// Get returns the effective value of a key and where it came from: an
+// environment variable, the config file or the key's default. Values that
+// fail the key's checks, e.g. typed into 'cam config edit', are reported once
+// and replaced by the default.
+func (cs *ConfigStore) Get(name string) (string, string, error) {
+	k, ok := LookupKey(name)
+	if !ok {
+		return "", "", unknownKeyError(name)
+	}
+
+	value, source, err := cs.configured(k)
+	if err != nil {
+		if _, warned := invalidWarned.LoadOrStore(name, true); !warned {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using the default\n", err)
+		}
+		return k.Default, SourceDefault, nil
+	}
+	return value, source, nil
+}
+
+// invalidWarned records the keys Get has already warned about.
+var invalidWarned sync.Map
+
+// configured returns the key's value from the environment or the config file,
+// and an error if that value is invalid.
+func (cs *ConfigStore) configured(k Key) (string, string, error) {
+	if v, ok := k.envValue(); ok {
+		if err := k.Check(v); err != nil {
+			return "", SourceEnv, fmt.Errorf("%s: %w", k.EnvVar(), err)
+		}
+		return v, SourceEnv, nil
+	}
+
+	cs.mu.RLock()
+	v, ok := cs.values[k.Name]
+	cs.mu.RUnlock()
+	if !ok {
+		return k.Default, SourceDefault, nil
+	}
+	if err := k.Check(v); err != nil {
+		return "", SourceFile, err
+	}
+	return v, SourceFile, nil
+}
+
+// Validate checks every configured value and returns the problems found.
+func (cs *ConfigStore) Validate() []error {
+	var errs []error
+	for _, k := range Keys() {
+		if _, _, err := cs.configured(k); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Set validates value for the key and saves it to the config file.
+func (cs *ConfigStore) Set(name string, value string) error {
+	k, ok := LookupKey(name)
+	if !ok {
+		return unknownKeyError(name)
+	}
+	if k.Type == TypeList {
+		value = strings.Join(splitList(value), ",")
+	}
+	if err := k.Check(value); err != nil {
+		return err
+	}
+
+	cs.mu.Lock()
+	cs.values[name] = value
+	cs.mu.Unlock()
+	return cs.SaveConfig()
+}
+
+// Unset removes the key from the config file so its default applies again.
+func (cs *ConfigStore) Unset(name string) error {
+	if _, ok := LookupKey(name); !ok {
+		return unknownKeyError(name)
+	}
+
 	cs.mu.Lock()
-	cs.Config.OllamaModel = model
+	delete(cs.values, name)
 	cs.mu.Unlock()
 	return cs.SaveConfig()
 }
 
+// String returns the effective value of a key.
+func (cs *ConfigStore) String(name string) string {
+	v, _, _ := cs.Get(name)
+	return v
+}
+
+// Int returns the effective value of an int key, falling back to its default
+// when the configured value is invalid.
+func (cs *ConfigStore) Int(name string) int {
+	if n, err := strconv.Atoi(cs.String(name)); err == nil {
+		return n
+	}
+	k, _ := LookupKey(name)
+	n, _ := strconv.Atoi(k.Default)
+	return n
+}
+
+// Bool returns the effective value of a bool key.
+func (cs *ConfigStore) Bool(name string) bool {
+	b, err := strconv.ParseBool(cs.String(name))
+	if err != nil {
+		k, _ := LookupKey(name)
+		b, _ = strconv.ParseBool(k.Default)
+	}
+	return b
+}
+
+// Duration returns the effective value of a duration key, falling back to its
+// default when the configured value is invalid.
+func (cs *ConfigStore) Duration(name string) time.Duration {
+	if d, err := parseDuration(cs.String(name)); err == nil {
+		return d
+	}
+	k, _ := LookupKey(name)
+	d, _ := parseDuration(k.Default)
+	return d
+}
+
+// List returns the effective value of a list key.
+func (cs *ConfigStore) List(name string) []string {
+	return splitList(cs.String(name))
+}
+
 func (cs *ConfigStore) GetOllamaModel() string {
-	cs.mu.RLock()
-	defer cs.mu.RUnlock()
-	if cs.Config.OllamaModel == "" {
-		return "qwen2.5-coder:7b"
+	return cs.String(KeyModel)
+}
+
+func (cs *ConfigStore) GetEmbedModel() string {
+	return cs.String(KeyEmbedModel)
+}
+
+// GetTaskModel returns the model for a task, falling back to the default model.
+func (cs *ConfigStore) GetTaskModel(task string) string {
+	var model string
+	switch task {
+	case TaskAsk:
+		model = cs.String(KeyAskModel)
+	case TaskCmdr:
+		model = cs.String(KeyCmdrModel)
+	case TaskEmbed:
+		return cs.GetEmbedModel()
+	}
+
+	if model == "" {
+		return cs.GetOllamaModel()
+	}
+	return model
+}
+
+// GetContextTokens returns the token budget for AI context.
+func (cs *ConfigStore) GetContextTokens() int {
+	return cs.Int(KeyContextTokens)
+}
+
+// GetContextProviders returns the configured providers, or nil to use each command's defaults.
+func (cs *ConfigStore) GetContextProviders() []string {
+	return cs.List(KeyContext)
+}
+
+// GetCacheTTL returns how long AI responses are cached. Zero disables the cache.
+func (cs *ConfigStore) GetCacheTTL() time.Duration {
+	return cs.Duration(KeyCacheTTL)
+}
+
+// GetTimeout returns how long to wait for an AI answer. Zero means no limit.
+func (cs *ConfigStore) GetTimeout() time.Duration {
+	return cs.Duration(KeyTimeout)
+}
+
+// GetCacheMaxBytes returns the response cache size limit in bytes.
+func (cs *ConfigStore) GetCacheMaxBytes() int64 {
+	return int64(cs.Int(KeyCacheSize)) * 1024 * 1024
+}
+
+func unknownKeyError(name string) error {
+	return fmt.Errorf("unknown configuration key: '%s' (see 'cam config list')", name)
+}
+
+// stringify converts a decoded JSON value to the string form used by the registry.
+func stringify(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, stringify(item))
+		}
+		return strings.Join(items, ",")
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// typedValue converts a stored string to the JSON type written to config.json.
+func typedValue(k Key, v string) any {
+	switch k.Type {
+	case TypeInt:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	case TypeBool:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	case TypeList:
+		return splitList(v)
 	}
-	return cs.Config.OllamaModel
+	return v
 }
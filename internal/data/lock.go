@@ -0,0 +1,42 @@
+package data
+
+import (
+	"path/filepath"
+)
+
+// lockFile guards load-modify-save cycles on data.json across processes.
+const lockFile = "data.lock"
+
+// Lock takes the lock Update holds, for changes to data.json made outside a
+// DataStore such as a sync merge. Call the returned function to release it.
+func Lock() (func(), error) {
+	dir, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
+	return lockPath(filepath.Join(dir, lockFile))
+}
+
+// Update loads the stacks with private commands decrypted, calls fn and
+// saves the result, holding an exclusive lock on the data directory
+// throughout so concurrent updates (from other cam commands or cam serve)
+// don't lose each other's changes. Nothing is saved when fn fails.
+// Use it on a new DataStore.
+func (ds *DataStore) Update(fn func() error) error {
+	if ds.err != nil {
+		return ds.err
+	}
+	unlock, err := lockPath(filepath.Join(filepath.Dir(ds.path), lockFile))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := ds.LoadData(true); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return ds.SaveData()
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package data
+
+import "sync"
+
+// updateMu stands in for a file lock where flock isn't available, so
+// updates are only serialised within this process.
+var updateMu sync.Mutex
+
+func lockPath(string) (func(), error) {
+	updateMu.Lock()
+	return updateMu.Unlock, nil
+}
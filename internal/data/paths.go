@@ -0,0 +1,221 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfile is the profile stored directly in cam's config and data
+// directories, as before profiles existed.
+const DefaultProfile = "default"
+
+// profileFile, in the config root, names the profile chosen with 'cam profile use'.
+const profileFile = "profile"
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// homeOverride and profileOverride are set from the --home and --profile flags.
+var (
+	homeOverride    string
+	profileOverride string
+)
+
+// SetHome makes cam keep its config and data in path (the --home flag).
+func SetHome(path string) {
+	homeOverride = path
+}
+
+// SetProfile selects a profile for this run (the --profile flag).
+func SetProfile(name string) {
+	profileOverride = name
+}
+
+// Roots returns cam's config and data directories before profile selection.
+// --home or $CAM_HOME holds both; otherwise $XDG_CONFIG_HOME/cam and
+// $XDG_DATA_HOME/cam are used, with data kept next to the config in
+// ~/.config/cam unless XDG_DATA_HOME is set. Data that predates
+// XDG_DATA_HOME support, i.e. a data.json next to the config while
+// $XDG_DATA_HOME/cam has none, keeps being used where it is.
+func Roots() (configRoot string, dataRoot string, err error) {
+	home := homeOverride
+	if home == "" {
+		home = os.Getenv("CAM_HOME")
+	}
+	if home != "" {
+		home = expandHome(home)
+		return home, home, nil
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		configRoot = filepath.Join(xdg, "cam")
+	} else {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", fmt.Errorf("could not find user home directory (set CAM_HOME or use --home): %w", err)
+		}
+		configRoot = filepath.Join(userHome, ".config", "cam")
+	}
+
+	dataRoot = configRoot
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" && !legacyData(configRoot, filepath.Join(xdg, "cam")) {
+		dataRoot = filepath.Join(xdg, "cam")
+	}
+	return configRoot, dataRoot, nil
+}
+
+// legacyData reports whether configRoot holds stacks and dataRoot doesn't.
+func legacyData(configRoot, dataRoot string) bool {
+	if _, err := os.Stat(filepath.Join(dataRoot, "data.json")); err == nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(configRoot, "data.json"))
+	return err == nil
+}
+
+// DataRoot returns the data directory shared by every profile.
+func DataRoot() (string, error) {
+	_, dataRoot, err := Roots()
+	return dataRoot, err
+}
+
+// ConfigDir returns the active profile's config directory (config.json, prompts).
+func ConfigDir() (string, error) {
+	configDir, _, err := activeDirs()
+	return configDir, err
+}
+
+// DataDir returns the active profile's data directory (data.json, keys, caches).
+func DataDir() (string, error) {
+	_, dataDir, err := activeDirs()
+	return dataDir, err
+}
+
+func activeDirs() (string, string, error) {
+	name := ActiveProfile()
+	configDir, dataDir, err := ProfileDirs(name)
+	if err != nil {
+		return "", "", err
+	}
+	if name != DefaultProfile {
+		if _, err := os.Stat(configDir); os.IsNotExist(err) {
+			return "", "", fmt.Errorf("profile '%s' does not exist (create it with 'cam profile create %s')", name, name)
+		}
+	}
+	return configDir, dataDir, nil
+}
+
+// ProfileDirs returns the config and data directories of a profile, whether or not it exists.
+func ProfileDirs(name string) (string, string, error) {
+	configRoot, dataRoot, err := Roots()
+	if err != nil {
+		return "", "", err
+	}
+	if name == DefaultProfile {
+		return configRoot, dataRoot, nil
+	}
+	if !profileName.MatchString(name) {
+		return "", "", fmt.Errorf("invalid profile name '%s'", name)
+	}
+	return filepath.Join(configRoot, "profiles", name), filepath.Join(dataRoot, "profiles", name), nil
+}
+
+// ActiveProfile returns the profile in use: --profile, then $CAM_PROFILE,
+// then the one chosen with 'cam profile use', then the default profile.
+func ActiveProfile() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	if env := os.Getenv("CAM_PROFILE"); env != "" {
+		return env
+	}
+	if configRoot, _, err := Roots(); err == nil {
+		if content, err := os.ReadFile(filepath.Join(configRoot, profileFile)); err == nil {
+			if name := strings.TrimSpace(string(content)); name != "" {
+				return name
+			}
+		}
+	}
+	return DefaultProfile
+}
+
+// Profiles lists the existing profiles, starting with the default one.
+func Profiles() ([]string, error) {
+	configRoot, _, err := Roots()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(configRoot, "profiles"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && profileName.MatchString(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// CreateProfile creates the directories of a new profile.
+func CreateProfile(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("profile '%s' already exists", name)
+	}
+	configDir, dataDir, err := ProfileDirs(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(configDir); err == nil {
+		return fmt.Errorf("profile '%s' already exists", name)
+	}
+
+	for _, dir := range []string{configDir, dataDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create profile directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseProfile makes name the profile used when --profile and $CAM_PROFILE are not set.
+func UseProfile(name string) error {
+	configDir, _, err := ProfileDirs(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(configDir); os.IsNotExist(err) && name != DefaultProfile {
+		return fmt.Errorf("profile '%s' does not exist (create it with 'cam profile create %s')", name, name)
+	}
+
+	configRoot, _, err := Roots()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(configRoot, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if name == DefaultProfile {
+		if err := os.Remove(filepath.Join(configRoot, profileFile)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset profile: %w", err)
+		}
+		return nil
+	}
+	return os.WriteFile(filepath.Join(configRoot, profileFile), []byte(name+"\n"), 0644)
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ProjectPrefix namespaces stacks discovered in the working directory.
+	ProjectPrefix = "project:"
+	// ProjectFile holds project stacks as {"stack": [commands]}, like data.json.
+	ProjectFile = ".cam.json"
+	// ProjectDir holds project stacks as one <stack>.json file of commands each.
+	ProjectDir = ".cam"
+)
+
+// Project is a directory carrying its own stacks in .cam.json and/or .cam/.
+type Project struct {
+	Root string
+}
+
+// FindProject walks up from dir to the nearest directory with a .cam.json
+// file or .cam directory.
+func FindProject(dir string) (*Project, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, false
+	}
+
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ProjectFile)); err == nil && info.Mode().IsRegular() {
+			return &Project{Root: dir}, true
+		}
+		if info, err := os.Stat(filepath.Join(dir, ProjectDir)); err == nil && info.IsDir() {
+			return &Project{Root: dir}, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, false
+		}
+		dir = parent
+	}
+}
+
+// NewProject returns the project a new stack should be written to when none
+// exists yet: the enclosing git repository, or dir itself.
+func NewProject(dir string) *Project {
+	dir, _ = filepath.Abs(dir)
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return &Project{Root: d}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return &Project{Root: dir}
+		}
+		d = parent
+	}
+}
+
+// Stacks reads the project's stacks, without the project: prefix. Stacks in
+// .cam/ are added after those in .cam.json. Private commands are ignored,
+// since project files are shared in plain text.
+func (p *Project) Stacks() (map[string][]Command, error) {
+	stacks := make(map[string][]Command)
+
+	if content, err := os.ReadFile(filepath.Join(p.Root, ProjectFile)); err == nil {
+		if err := json.Unmarshal(content, &stacks); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(p.Root, ProjectFile), err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read project file: %w", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(p.Root, ProjectDir, "*.json"))
+	sort.Strings(files)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read project file: %w", err)
+		}
+		var commands []Command
+		if err := json.Unmarshal(content, &commands); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		stacks[name] = append(stacks[name], commands...)
+	}
+
+	for name, commands := range stacks {
+		var public []Command
+		for _, c := range commands {
+			if !c.IsPrivate && c.Cmd != "" {
+				public = append(public, c)
+			}
+		}
+		if len(public) > 0 {
+			stacks[name] = public
+		} else {
+			delete(stacks, name)
+		}
+	}
+	return stacks, nil
+}
+
+// AddCommand prepends a command to a project stack. Stacks stored in .cam/
+// stay there; anything else goes to .cam.json.
+func (p *Project) AddCommand(stackName string, cmdStr string, description string, tags []string) error {
+	// The name is joined into a path under .cam/, so it must not leave it.
+	if strings.ContainsAny(stackName, `/\`) || strings.Contains(stackName, "..") {
+		return fmt.Errorf("invalid project stack name '%s': it can't contain a path separator or '..'", stackName)
+	}
+
+	newCmd := Command{
+		Cmd:         cmdStr,
+		Description: description,
+		Tags:        tags,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+
+	stackFile := filepath.Join(p.Root, ProjectDir, stackName+".json")
+	if _, err := os.Stat(stackFile); err == nil {
+		var commands []Command
+		if content, err := os.ReadFile(stackFile); err == nil {
+			if err := json.Unmarshal(content, &commands); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", stackFile, err)
+			}
+		}
+		return writeJSON(stackFile, append([]Command{newCmd}, commands...))
+	}
+
+	path := filepath.Join(p.Root, ProjectFile)
+	stacks := make(map[string][]Command)
+	if content, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(content, &stacks); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read project file: %w", err)
+	}
+	stacks[stackName] = append([]Command{newCmd}, stacks[stackName]...)
+	return writeJSON(path, stacks)
+}
+
+func writeJSON(path string, v any) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
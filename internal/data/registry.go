@@ -0,0 +1,162 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyType is the type of a configuration value.
+type KeyType string
+
+const (
+	TypeString   KeyType = "string"
+	TypeInt      KeyType = "int"
+	TypeBool     KeyType = "bool"
+	TypeDuration KeyType = "duration"
+	TypeList     KeyType = "list" // comma separated
+)
+
+// Configuration keys.
+const (
+	KeyModel         = "model"
+	KeyAskModel      = "ask-model"
+	KeyCmdrModel     = "cmdr-model"
+	KeyEmbedModel    = "embed-model"
+	KeyOllamaHost    = "ollama-host"
+	KeyContext       = "context"
+	KeyContextTokens = "context-tokens"
+	KeyCacheTTL      = "cache-ttl"
+	KeyCacheSize     = "cache-size"
+	KeyTimeout       = "timeout"
+	KeyShell         = "shell"
+	KeyColor         = "color"
+)
+
+// Key describes one configuration setting.
+type Key struct {
+	Name        string
+	Type        KeyType
+	Default     string // empty means unset
+	Description string
+	// Validate checks a value after its type has been checked. It may be nil.
+	Validate func(value string) error
+	// legacy is the config.json field this key was stored in before the registry.
+	legacy string
+}
+
+var registry = []Key{
+	{Name: KeyModel, Type: TypeString, Default: "qwen2.5-coder:7b", legacy: "ollama_model",
+		Description: "Default Ollama model for every task"},
+	{Name: KeyAskModel, Type: TypeString, legacy: "ask_model",
+		Description: "Model for ask (defaults to model)"},
+	{Name: KeyCmdrModel, Type: TypeString, legacy: "cmdr_model",
+		Description: "Model for cmdr, fix, gen-stack and enrich (defaults to model)"},
+	{Name: KeyEmbedModel, Type: TypeString, Default: "nomic-embed-text", legacy: "embed_model",
+		Description: "Ollama embedding model used by search"},
+	{Name: KeyOllamaHost, Type: TypeString,
+		Description: "Ollama API address (defaults to $OLLAMA_HOST or 127.0.0.1:11434)"},
+	{Name: KeyContext, Type: TypeList, legacy: "context_providers",
+		Description: "AI context providers for ask and cmdr (defaults per command)"},
+	{Name: KeyContextTokens, Type: TypeInt, Default: "2000", legacy: "context_tokens", Validate: positive,
+		Description: "Token budget for AI context"},
+	{Name: KeyCacheTTL, Type: TypeDuration, Default: "24h", legacy: "cache_ttl",
+		Description: `How long AI responses are cached ("0" disables caching)`},
+	{Name: KeyCacheSize, Type: TypeInt, Default: "50", legacy: "cache_max_mb", Validate: positive,
+		Description: "Response cache size limit in MB"},
+	{Name: KeyTimeout, Type: TypeDuration, Default: "5m",
+		Description: `How long to wait for an AI answer ("0" waits forever)`},
+	{Name: KeyShell, Type: TypeString,
+		Description: "Shell used to run commands (defaults to $SHELL, then /bin/sh)"},
+	{Name: KeyColor, Type: TypeString, Default: "auto", Validate: oneOf("auto", "always", "never"),
+		Description: `Colored output: "auto", "always" or "never"`},
+}
+
+// Keys returns every configuration key, sorted by name.
+func Keys() []Key {
+	keys := append([]Key(nil), registry...)
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys
+}
+
+// LookupKey returns the key with the given name.
+func LookupKey(name string) (Key, bool) {
+	for _, k := range registry {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// EnvVar returns the environment variable that overrides the key, e.g. CAM_CACHE_TTL.
+func (k Key) EnvVar() string {
+	return "CAM_" + strings.ToUpper(strings.ReplaceAll(k.Name, "-", "_"))
+}
+
+// Check reports whether value is valid for the key.
+func (k Key) Check(value string) error {
+	switch k.Type {
+	case TypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("invalid value for %s: %q is not an integer", k.Name, value)
+		}
+	case TypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid value for %s: %q is not true or false", k.Name, value)
+		}
+	case TypeDuration:
+		if _, err := parseDuration(value); err != nil {
+			return fmt.Errorf("invalid value for %s: %q is not a duration (e.g. \"12h\", \"30m\" or \"0\")", k.Name, value)
+		}
+	}
+	if k.Validate != nil {
+		if err := k.Validate(value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", k.Name, err)
+		}
+	}
+	return nil
+}
+
+// envValue returns the key's environment override, if set.
+func (k Key) envValue() (string, bool) {
+	return os.LookupEnv(k.EnvVar())
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	if value == "0" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func positive(value string) error {
+	if n, _ := strconv.Atoi(value); n <= 0 {
+		return fmt.Errorf("must be a positive number")
+	}
+	return nil
+}
+
+func oneOf(choices ...string) func(string) error {
+	return func(value string) error {
+		for _, c := range choices {
+			if value == c {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(choices, ", "))
+	}
+}
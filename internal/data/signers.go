@@ -0,0 +1,76 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// SignersFile lists the signers whose bundles and sources are trusted.
+const SignersFile = "trusted_signers.json"
+
+// Signer is a trusted bundle signer, identified by an Ed25519 public key
+// written "ed25519:<base64>".
+type Signer struct {
+	Name  string `json:"name"`
+	Key   string `json:"key"`
+	Added string `json:"added,omitempty"`
+}
+
+// TrustedSigners returns the trusted signers.
+func TrustedSigners() ([]Signer, error) {
+	path, err := signersPath()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", SignersFile, err)
+	}
+
+	var signers []Signer
+	if err := json.Unmarshal(content, &signers); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", SignersFile, err)
+	}
+	return signers, nil
+}
+
+// SaveTrustedSigners writes the list of trusted signers.
+func SaveTrustedSigners(signers []Signer) error {
+	path, err := signersPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if signers == nil {
+		signers = []Signer{}
+	}
+	return writeJSON(path, signers)
+}
+
+// TrustedSigner returns the trusted signer with the public key, if any.
+func TrustedSigner(key string) (Signer, bool) {
+	signers, err := TrustedSigners()
+	if err != nil {
+		return Signer{}, false
+	}
+	i := slices.IndexFunc(signers, func(s Signer) bool { return s.Key == key })
+	if i < 0 {
+		return Signer{}, false
+	}
+	return signers[i], true
+}
+
+func signersPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SignersFile), nil
+}
@@ -0,0 +1,185 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// SourcesFile lists the stack sources in the config directory.
+	SourcesFile = "sources.json"
+	// sourcesDir holds a snapshot of each source's stacks in the data directory.
+	sourcesDir = "sources"
+	// SourceSeparator joins a source and one of its stacks, e.g. "team/deploy".
+	SourceSeparator = "/"
+)
+
+// Kinds of stack sources.
+const (
+	SourceDir    = "dir"    // a directory with .cam.json and/or .cam/, read in place
+	SourceGit    = "git"    // a git repository laid out like a directory source, cloned
+	SourceBundle = "bundle" // a bundle written by 'cam export'
+)
+
+var sourceName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// StackSource is a subscription to stacks published elsewhere. Its stacks
+// appear read-only as "<name>/<stack>", from the snapshot taken by the last
+// 'cam sources update'.
+type StackSource struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Location string `json:"location"`
+	Updated  string `json:"updated,omitempty"`
+	// Signer is the public key that signed the source's bundle at the last
+	// update, or empty when it wasn't signed.
+	Signer string `json:"signer,omitempty"`
+}
+
+// StackSources returns the configured sources.
+func StackSources() ([]StackSource, error) {
+	path, err := sourcesPath()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", SourcesFile, err)
+	}
+
+	var sources []StackSource
+	if err := json.Unmarshal(content, &sources); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", SourcesFile, err)
+	}
+	return sources, nil
+}
+
+// SaveStackSources writes the list of sources.
+func SaveStackSources(sources []StackSource) error {
+	path, err := sourcesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if sources == nil {
+		sources = []StackSource{}
+	}
+	return writeJSON(path, sources)
+}
+
+// ValidateSourceName checks that name can namespace stacks.
+func ValidateSourceName(name string) error {
+	if !sourceName.MatchString(name) {
+		return fmt.Errorf("invalid source name '%s' (use lowercase letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// SourceCacheDir is where a source keeps its snapshot (<name>.json) and,
+// for git sources, its clone (<name>/).
+func SourceCacheDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sourcesDir), nil
+}
+
+// WriteSourceSnapshot stores the stacks fetched from a source.
+func WriteSourceSnapshot(name string, stacks map[string][]Command) error {
+	dir, err := SourceCacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return writeJSON(filepath.Join(dir, name+".json"), stacks)
+}
+
+// RemoveSourceCache deletes a source's snapshot and clone.
+func RemoveSourceCache(name string) error {
+	dir, err := SourceCacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, name+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(dir, name))
+}
+
+func sourcesPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SourcesFile), nil
+}
+
+// loadSourceStacks merges the snapshots of the configured sources as
+// read-only "<source>/<stack>" stacks.
+func (ds *DataStore) loadSourceStacks() {
+	sources, err := StackSources()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring stack sources: %v\n", err)
+		return
+	}
+
+	dir := filepath.Join(filepath.Dir(ds.path), sourcesDir)
+	for _, src := range sources {
+		ds.sources = append(ds.sources, src)
+
+		content, err := os.ReadFile(filepath.Join(dir, src.Name+".json"))
+		if os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Warning: source '%s' has not been fetched yet; run 'cam sources update'\n", src.Name)
+			continue
+		}
+		var stacks map[string][]Command
+		if err == nil {
+			err = json.Unmarshal(content, &stacks)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring source '%s': %v\n", src.Name, err)
+			continue
+		}
+
+		for stack, commands := range stacks {
+			name := src.Name + SourceSeparator + stack
+			if _, exists := ds.Stacks[name]; exists {
+				fmt.Fprintf(os.Stderr, "Warning: source stack '%s' is hidden by a personal stack with the same name\n", name)
+				continue
+			}
+			ds.Stacks[name] = commands
+			ds.readOnly[name] = true
+		}
+	}
+}
+
+// sourceOf returns the source a stack name belongs to, if any.
+func (ds *DataStore) sourceOf(stackName string) (StackSource, bool) {
+	for _, src := range ds.sources {
+		if strings.HasPrefix(stackName, src.Name+SourceSeparator) {
+			return src, true
+		}
+	}
+	return StackSource{}, false
+}
+
+// SourceOf returns the source a stack was loaded from, if any.
+func (ds *DataStore) SourceOf(stackName string) (StackSource, bool) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	if !ds.readOnly[stackName] {
+		return StackSource{}, false
+	}
+	return ds.sourceOf(stackName)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,44 +14,59 @@ import (
 )
 
 type Command struct {
-	Cmd       string   `json:"cmd,omitempty"`
-	Encrypted string   `json:"encrypted,omitempty"`
-	IsPrivate bool     `json:"is_private"`
-	Tags      []string `json:"tags"`
-	Timestamp string   `json:"timestamp"`
+	Cmd         string   `json:"cmd,omitempty"`
+	Encrypted   string   `json:"encrypted,omitempty"`
+	Description string   `json:"description,omitempty"`
+	IsPrivate   bool     `json:"is_private"`
+	Tags        []string `json:"tags"`
+	Timestamp   string   `json:"timestamp"`
+
+	// decrypted is Cmd as decrypted on load, so unchanged private commands
+	// keep their ciphertext instead of being re-encrypted on every save.
+	decrypted string
 }
 
 type DataStore struct {
 	Stacks map[string][]Command `json:"stacks"`
 	path   string
-	mu     sync.RWMutex
+	err    error // why path couldn't be determined, reported by LoadData and SaveData
+	// readOnly marks stacks merged in from elsewhere (e.g. project stacks);
+	// they can't be modified and are never written to data.json.
+	readOnly map[string]bool
+	// sources namespace their stacks ("team/"): no stack under them can be
+	// created or changed locally.
+	sources []StackSource
+	mu      sync.RWMutex
 }
 
 func NewDataStore() *DataStore {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "CRITICAL: Could not find user home directory: %v\n", err)
-		os.Exit(1)
+	ds := &DataStore{
+		Stacks:   make(map[string][]Command),
+		readOnly: make(map[string]bool),
 	}
 
-	path := filepath.Join(home, ".config", "cam", "data.json")
-
-	return &DataStore{
-		Stacks: make(map[string][]Command),
-		path:   path,
+	dir, err := DataDir()
+	if err != nil {
+		ds.err = err
+		return ds
 	}
+	ds.path = filepath.Join(dir, "data.json")
+	return ds
 }
 
 func (ds *DataStore) LoadData(decryptPrivate bool) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
-	if ds.path == "" {
-		return fmt.Errorf("could not determine config path")
+	if ds.err != nil {
+		return ds.err
 	}
 
 	data, err := os.ReadFile(ds.path)
-	if err != nil {
+	if os.IsNotExist(err) {
+		// Nothing pinned yet (fresh install or new profile).
+		data = []byte("{}")
+	} else if err != nil {
 		return fmt.Errorf("failed to read data file: %w", err)
 	}
 
@@ -62,6 +78,8 @@ func (ds *DataStore) LoadData(decryptPrivate bool) error {
 	if ds.Stacks == nil {
 		ds.Stacks = make(map[string][]Command)
 	}
+	ds.loadProjectStacks()
+	ds.loadSourceStacks()
 
 	if !decryptPrivate {
 		for stackName, commands := range ds.Stacks {
@@ -96,6 +114,7 @@ func (ds *DataStore) LoadData(decryptPrivate bool) error {
 						} else {
 							commands[i].Cmd = "[DECRYPTION FAILED]"
 						}
+						commands[i].decrypted = commands[i].Cmd
 					}
 				}
 			}
@@ -107,6 +126,10 @@ func (ds *DataStore) LoadData(decryptPrivate bool) error {
 }
 
 func (ds *DataStore) SaveData() error {
+	if ds.err != nil {
+		return ds.err
+	}
+
 	ds.mu.RLock()
 
 	saveStacks := make(map[string][]Command)
@@ -116,11 +139,16 @@ func (ds *DataStore) SaveData() error {
 	pubKeyPath := filepath.Join(keysDir, "public_key.pem")
 
 	for k, v := range ds.Stacks {
+		if ds.readOnly[k] {
+			continue
+		}
 		saveCmds := make([]Command, len(v))
 		for i, c := range v {
 			saveCmds[i] = c
 			if c.IsPrivate {
-				if c.Cmd != "" {
+				if c.Encrypted != "" && c.Cmd == c.decrypted {
+					saveCmds[i].Cmd = ""
+				} else if c.Cmd != "" {
 					cipherBytes, err := crypto.Encrypt([]byte(c.Cmd), pubKeyPath)
 					if err == nil {
 						saveCmds[i].Encrypted = base64.StdEncoding.EncodeToString(cipherBytes)
@@ -146,33 +174,61 @@ func (ds *DataStore) SaveData() error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	if err := os.WriteFile(ds.path, data, 0644); err != nil {
+	// Write a temporary file and rename it so readers never see a partial file.
+	tmp, err := os.CreateTemp(dir, "data-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write data file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), ds.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
 		return fmt.Errorf("failed to write data file: %w", err)
 	}
 
+	for _, hook := range saveHooks {
+		hook(ds.path)
+	}
 	return nil
 }
 
-func (ds *DataStore) AddCommand(stackName string, cmdStr string, tags []string, isPrivate bool) error {
+// saveHooks run after data.json has been written.
+var saveHooks []func(path string)
+
+// OnSave registers a function called with the data file's path after every
+// successful save, e.g. to commit it for sync.
+func OnSave(hook func(path string)) {
+	saveHooks = append(saveHooks, hook)
+}
+
+func (ds *DataStore) AddCommand(stackName string, cmdStr string, description string, tags []string, isPrivate bool) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
+	if ds.isReadOnly(stackName) {
+		return ds.readOnlyError(stackName)
+	}
+
 	if isPrivate {
-		configDir := filepath.Dir(ds.path)
-		keysDir := filepath.Join(configDir, ".keys")
-		if err := os.MkdirAll(keysDir, 0700); err != nil {
-			return fmt.Errorf("failed to create keys directory: %w", err)
-		}
-		if err := crypto.EnsureKeysExists(keysDir); err != nil {
-			return fmt.Errorf("failed to ensure encryption keys: %w", err)
+		if _, err := ds.ensureKeys(); err != nil {
+			return err
 		}
 	}
 
 	newCmd := Command{
-		Cmd:       cmdStr,
-		Tags:      tags,
-		IsPrivate: isPrivate,
-		Timestamp: time.Now().Format(time.RFC3339),
+		Cmd:         cmdStr,
+		Description: description,
+		Tags:        tags,
+		IsPrivate:   isPrivate,
+		Timestamp:   time.Now().Format(time.RFC3339),
 	}
 
 	currentStack := ds.Stacks[stackName]
@@ -197,6 +253,10 @@ func (ds *DataStore) RemoveCommand(stackName string, index int) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
+	if ds.isReadOnly(stackName) {
+		return ds.readOnlyError(stackName)
+	}
+
 	stack, exists := ds.Stacks[stackName]
 	if !exists {
 		return fmt.Errorf("stack '%s' does not exist", stackName)
@@ -215,6 +275,10 @@ func (ds *DataStore) RemoveStack(stackName string) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
+	if ds.isReadOnly(stackName) {
+		return ds.readOnlyError(stackName)
+	}
+
 	if _, exists := ds.Stacks[stackName]; !exists {
 		return fmt.Errorf("stack '%s' does not exist", stackName)
 	}
@@ -227,6 +291,10 @@ func (ds *DataStore) Swap(stackName string, i, j int) error {
 	ds.mu.Lock()
 	defer ds.mu.Unlock()
 
+	if ds.isReadOnly(stackName) {
+		return ds.readOnlyError(stackName)
+	}
+
 	stack, exists := ds.Stacks[stackName]
 	if !exists {
 		return fmt.Errorf("stack '%s' does not exist", stackName)
@@ -244,3 +312,99 @@ func (ds *DataStore) Swap(stackName string, i, j int) error {
 
 	return nil
 }
+
+// SetStack replaces the commands of a stack, creating it if needed. Private
+// commands are given in plain text and encrypted on save.
+func (ds *DataStore) SetStack(stackName string, commands []Command) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if ds.isReadOnly(stackName) {
+		return ds.readOnlyError(stackName)
+	}
+
+	for _, c := range commands {
+		if c.IsPrivate {
+			if _, err := ds.ensureKeys(); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	ds.Stacks[stackName] = commands
+	return nil
+}
+
+// EnsureKeys creates the encryption keys for private commands if needed and
+// returns the directory holding them.
+func (ds *DataStore) EnsureKeys() (string, error) {
+	if ds.err != nil {
+		return "", ds.err
+	}
+	return ds.ensureKeys()
+}
+
+func (ds *DataStore) ensureKeys() (string, error) {
+	keysDir := filepath.Join(filepath.Dir(ds.path), ".keys")
+	if err := os.MkdirAll(keysDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create keys directory: %w", err)
+	}
+	if err := crypto.EnsureKeysExists(keysDir); err != nil {
+		return "", fmt.Errorf("failed to ensure encryption keys: %w", err)
+	}
+	return keysDir, nil
+}
+
+// IsReadOnly reports whether a stack was merged in from elsewhere and can't be changed.
+func (ds *DataStore) IsReadOnly(stackName string) bool {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	return ds.isReadOnly(stackName)
+}
+
+func (ds *DataStore) isReadOnly(stackName string) bool {
+	if ds.readOnly[stackName] {
+		return true
+	}
+	_, ok := ds.sourceOf(stackName)
+	return ok
+}
+
+// loadProjectStacks merges the stacks of the project around the working
+// directory as read-only "project:<stack>" stacks.
+func (ds *DataStore) loadProjectStacks() {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	project, ok := FindProject(cwd)
+	if !ok {
+		return
+	}
+
+	stacks, err := project.Stacks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring project stacks: %v\n", err)
+		return
+	}
+	for name, commands := range stacks {
+		name = ProjectPrefix + name
+		if _, exists := ds.Stacks[name]; exists {
+			fmt.Fprintf(os.Stderr, "Warning: project stack '%s' is hidden by a personal stack with the same name\n", name)
+			continue
+		}
+		ds.Stacks[name] = commands
+		ds.readOnly[name] = true
+	}
+}
+
+func (ds *DataStore) readOnlyError(stackName string) error {
+	if src, ok := ds.sourceOf(stackName); ok {
+		return fmt.Errorf("stack '%s' comes from the source '%s' and is read-only; refresh it with 'cam sources update'", stackName, src.Name)
+	}
+	if strings.HasPrefix(stackName, ProjectPrefix) {
+		return fmt.Errorf("stack '%s' is read-only; edit the project's %s or use 'cam pin --project'", stackName, ProjectFile)
+	}
+	return fmt.Errorf("stack '%s' is read-only", stackName)
+}
@@ -0,0 +1,307 @@
+// Package gitsync keeps the data directory in a git repository so stacks
+// can be synced between machines. Only data.json is tracked: private
+// commands stay encrypted in history and the keys never leave the machine.
+// Concurrent edits are combined with a stack-aware three-way merge (see
+// Merge) instead of textual conflicts in the JSON.
+package gitsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cam/internal/data"
+)
+
+const (
+	Remote   = "origin"
+	Branch   = "main"
+	DataFile = "data.json"
+
+	// gitignore keeps everything but the stacks out of the repository:
+	// keys, caches and embeddings are per machine.
+	gitignore = "*\n!.gitignore\n!" + DataFile + "\n"
+)
+
+var ErrNotInitialized = errors.New("sync is not set up; run 'cam sync init [remote]'")
+
+// Repo is the git repository in a data directory.
+type Repo struct {
+	Dir string
+
+	identity []string
+}
+
+// Enabled reports whether dir is synced.
+func Enabled(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// Open returns the repository in dir, or ErrNotInitialized.
+func Open(dir string) (*Repo, error) {
+	if !Enabled(dir) {
+		return nil, ErrNotInitialized
+	}
+	return &Repo{Dir: dir}, nil
+}
+
+// Init sets up a repository in dir (keeping an existing one) and points it
+// at remote when given.
+func Init(dir, remote string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("sync needs git installed: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	r := &Repo{Dir: dir}
+	if !Enabled(dir) {
+		if _, err := r.git("init", "-q"); err != nil {
+			return nil, err
+		}
+		if _, err := r.git("symbolic-ref", "HEAD", "refs/heads/"+Branch); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(gitignore), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write .gitignore: %w", err)
+	}
+	if remote != "" {
+		if err := r.SetRemote(remote); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := r.Commit("Set up cam sync"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RemoteURL returns the configured remote, or "" if there is none.
+func (r *Repo) RemoteURL() string {
+	url, err := r.git("remote", "get-url", Remote)
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// SetRemote adds or changes the remote. Relative paths are made absolute so
+// they keep working from the data directory.
+func (r *Repo) SetRemote(url string) error {
+	if info, err := os.Stat(url); err == nil && info.IsDir() {
+		if abs, err := filepath.Abs(url); err == nil {
+			url = abs
+		}
+	}
+	if r.RemoteURL() == "" {
+		_, err := r.git("remote", "add", Remote, url)
+		return err
+	}
+	_, err := r.git("remote", "set-url", Remote, url)
+	return err
+}
+
+// Commit records the current stacks. It reports whether there was anything to commit.
+func (r *Repo) Commit(message string) (bool, error) {
+	if _, err := r.git("add", "--", ".gitignore", DataFile); err != nil {
+		// data.json doesn't exist until something is pinned.
+		if _, err := r.git("add", "--", ".gitignore"); err != nil {
+			return false, err
+		}
+	}
+	if _, err := r.git("diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+	if _, err := r.git("commit", "-q", "-m", message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Result describes what a sync did.
+type Result struct {
+	Committed bool // local changes were committed first
+	Pulled    int  // commits received from the remote
+	Merged    bool // local and remote changes were merged
+	Pushed    int  // commits sent to the remote
+}
+
+// Sync commits local changes, merges the remote's and pushes the result.
+func (r *Repo) Sync() (Result, error) {
+	var res Result
+	var err error
+	if res.Committed, err = r.Commit("Update stacks"); err != nil {
+		return res, err
+	}
+	if r.RemoteURL() == "" {
+		return res, fmt.Errorf("no remote configured; run 'cam sync init <remote>'")
+	}
+	if _, err := r.git("fetch", "-q", Remote); err != nil {
+		return res, err
+	}
+
+	remoteRef := "refs/remotes/" + Remote + "/" + Branch
+	hasRemote := r.hasRef(remoteRef)
+	hasLocal := r.hasRef("HEAD")
+
+	switch {
+	case !hasRemote:
+	case !hasLocal || r.isAncestor("HEAD", remoteRef):
+		res.Pulled = r.count("HEAD.." + remoteRef)
+		if !hasLocal {
+			res.Pulled = r.count(remoteRef)
+		}
+		if _, err := r.git("merge", "-q", "--ff-only", remoteRef); err != nil {
+			return res, err
+		}
+	case r.isAncestor(remoteRef, "HEAD"):
+	default:
+		res.Pulled = r.count("HEAD.." + remoteRef)
+		if err := r.merge(remoteRef); err != nil {
+			return res, err
+		}
+		res.Merged = true
+	}
+
+	if !r.hasRef("HEAD") {
+		return res, nil
+	}
+	if hasRemote {
+		res.Pushed = r.count(remoteRef + "..HEAD")
+	} else {
+		res.Pushed = r.count("HEAD")
+	}
+	if res.Pushed > 0 {
+		if _, err := r.git("push", "-q", Remote, "HEAD:refs/heads/"+Branch); err != nil {
+			return res, fmt.Errorf("%w (the remote may have changed meanwhile; run 'cam sync' again)", err)
+		}
+		r.git("fetch", "-q", Remote)
+	}
+	return res, nil
+}
+
+// merge combines the remote's stacks with ours into a merge commit. git
+// only records the history; the content comes from Merge.
+func (r *Repo) merge(remoteRef string) error {
+	base := map[string][]data.Command{}
+	if mergeBase, err := r.git("merge-base", "HEAD", remoteRef); err == nil {
+		if base, err = r.stacksAt(mergeBase); err != nil {
+			return err
+		}
+	}
+	ours, err := r.stacksAt("HEAD")
+	if err != nil {
+		return err
+	}
+	theirs, err := r.stacksAt(remoteRef)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(Merge(base, ours, theirs), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal merged stacks: %w", err)
+	}
+
+	if _, err := r.git("merge", "-q", "--no-ff", "--no-commit", "--allow-unrelated-histories", "-s", "ours", remoteRef); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, DataFile), content, 0644); err != nil {
+		r.git("merge", "--abort")
+		return fmt.Errorf("failed to write merged stacks: %w", err)
+	}
+	if _, err := r.git("add", "--", DataFile); err != nil {
+		return err
+	}
+	_, err = r.git("commit", "-q", "-m", "Merge stacks from "+Remote)
+	return err
+}
+
+// stacksAt reads data.json as of rev; a revision without it has no stacks.
+func (r *Repo) stacksAt(rev string) (map[string][]data.Command, error) {
+	stacks := map[string][]data.Command{}
+	content, err := r.git("show", rev+":"+DataFile)
+	if err != nil {
+		return stacks, nil
+	}
+	if err := json.Unmarshal([]byte(content), &stacks); err != nil {
+		return nil, fmt.Errorf("failed to parse %s at %s: %w", DataFile, rev, err)
+	}
+	return stacks, nil
+}
+
+// Status summarises the repository.
+type Status struct {
+	Remote     string
+	LastCommit string // "<hash> <subject> (<when>)"
+	Ahead      int    // local commits not pushed (as of the last fetch)
+	Behind     int    // remote commits not merged (as of the last fetch)
+}
+
+func (r *Repo) Status() (Status, error) {
+	st := Status{Remote: r.RemoteURL()}
+	if r.hasRef("HEAD") {
+		st.LastCommit, _ = r.git("log", "-1", "--format=%h %s (%cr)")
+	}
+	remoteRef := "refs/remotes/" + Remote + "/" + Branch
+	if r.hasRef(remoteRef) && r.hasRef("HEAD") {
+		st.Ahead = r.count(remoteRef + "..HEAD")
+		st.Behind = r.count("HEAD.." + remoteRef)
+	} else if r.hasRef("HEAD") {
+		st.Ahead = r.count("HEAD")
+	}
+	return st, nil
+}
+
+func (r *Repo) hasRef(ref string) bool {
+	_, err := r.git("rev-parse", "--verify", "-q", ref)
+	return err == nil
+}
+
+func (r *Repo) isAncestor(a, b string) bool {
+	_, err := r.git("merge-base", "--is-ancestor", a, b)
+	return err == nil
+}
+
+func (r *Repo) count(revs string) int {
+	out, err := r.git("rev-list", "--count", revs)
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(out)
+	return n
+}
+
+// git runs a git command in the repository and returns its trimmed output.
+// Commits fall back to a cam identity when the user hasn't configured one.
+func (r *Repo) git(args ...string) (string, error) {
+	if r.identity == nil {
+		r.identity = []string{}
+		if out, err := exec.Command("git", "-C", r.Dir, "config", "user.email").Output(); err != nil || len(bytes.TrimSpace(out)) == 0 {
+			r.identity = []string{"-c", "user.name=cam", "-c", "user.email=cam@localhost"}
+		}
+	}
+
+	full := append(append([]string{"-C", r.Dir}, r.identity...), args...)
+	cmd := exec.Command("git", full...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
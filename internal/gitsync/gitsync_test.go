@@ -0,0 +1,99 @@
+package gitsync
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeStacks(t *testing.T, dir string, s stacks) {
+	t.Helper()
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, DataFile), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readStacks(t *testing.T, dir string) stacks {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, DataFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s stacks
+	if err := json.Unmarshal(content, &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSyncRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// Keep the user's git config out, so commits use cam's fallback identity.
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v: %s", err, out)
+	}
+
+	laptop, desktop := filepath.Join(root, "laptop"), filepath.Join(root, "desktop")
+	a, err := Init(laptop, remote)
+	if err != nil {
+		t.Fatalf("Init laptop: %v", err)
+	}
+	writeStacks(t, laptop, stacks{"git": {cmd("git status"), cmd("git log")}})
+	if res, err := a.Sync(); err != nil || !res.Committed || res.Pushed == 0 {
+		t.Fatalf("first sync = %+v, %v", res, err)
+	}
+
+	b, err := Init(desktop, remote)
+	if err != nil {
+		t.Fatalf("Init desktop: %v", err)
+	}
+	if res, err := b.Sync(); err != nil || res.Pulled == 0 {
+		t.Fatalf("desktop sync = %+v, %v", res, err)
+	}
+	if got := readStacks(t, desktop); !reflect.DeepEqual(got, readStacks(t, laptop)) {
+		t.Fatalf("desktop stacks = %#v after pulling", got)
+	}
+
+	// Concurrent edits on both machines.
+	writeStacks(t, laptop, stacks{"git": {cmd("git pull"), cmd("git status"), cmd("git log")}})
+	writeStacks(t, desktop, stacks{"git": {described("git status", "short")}, "k8s": {cmd("kubectl get pods")}})
+	if _, err := a.Sync(); err != nil {
+		t.Fatalf("laptop sync: %v", err)
+	}
+	res, err := b.Sync()
+	if err != nil || !res.Merged {
+		t.Fatalf("desktop merge = %+v, %v", res, err)
+	}
+	if _, err := a.Sync(); err != nil {
+		t.Fatalf("laptop sync after merge: %v", err)
+	}
+
+	want := stacks{
+		"git": {cmd("git pull"), described("git status", "short")},
+		"k8s": {cmd("kubectl get pods")},
+	}
+	for _, dir := range []string{laptop, desktop} {
+		if got := readStacks(t, dir); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s stacks =\n%#v\nwant\n%#v", filepath.Base(dir), got, want)
+		}
+	}
+
+	st, err := a.Status()
+	if err != nil || st.Ahead != 0 || st.Behind != 0 {
+		t.Errorf("laptop status = %+v, %v; want in sync", st, err)
+	}
+}
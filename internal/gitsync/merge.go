@@ -0,0 +1,165 @@
+package gitsync
+
+import (
+	"slices"
+	"strconv"
+
+	"cam/internal/data"
+)
+
+// Merge combines two descendants (ours and theirs) of the stacks in base.
+//
+// Commands are matched by their text, or their ciphertext when private,
+// so the JSON layout never matters:
+//   - a command deleted on one side is deleted, unless it was re-added;
+//   - commands added on either side are kept, placed after the command
+//     that precedes them on that side (new pins go to the top);
+//   - a description or tag change on one side wins over an unchanged side,
+//     ours wins when both changed;
+//   - the order follows ours, unless only theirs reordered the stack;
+//   - a stack deleted on one side survives only with the other side's additions.
+func Merge(base, ours, theirs map[string][]data.Command) map[string][]data.Command {
+	names := make(map[string]bool)
+	for _, m := range []map[string][]data.Command{base, ours, theirs} {
+		for name := range m {
+			names[name] = true
+		}
+	}
+
+	result := make(map[string][]data.Command)
+	for name := range names {
+		_, inOurs := ours[name]
+		_, inTheirs := theirs[name]
+		if !inOurs && !inTheirs {
+			continue
+		}
+		merged := mergeStack(base[name], ours[name], theirs[name])
+		if len(merged) == 0 && (!inOurs || !inTheirs) {
+			continue
+		}
+		result[name] = merged
+	}
+	return result
+}
+
+type keyed struct {
+	key string
+	cmd data.Command
+}
+
+func mergeStack(base, ours, theirs []data.Command) []data.Command {
+	b, o, t := withKeys(base), withKeys(ours), withKeys(theirs)
+	inBase, inOurs, inTheirs := keySet(b), keySet(o), keySet(t)
+	baseCmds := make(map[string]data.Command)
+	for _, k := range b {
+		baseCmds[k.key] = k.cmd
+	}
+
+	// Keep the order of whichever side reordered; ours if both did.
+	skeleton, other, otherSet, skeletonIsOurs := o, t, inTheirs, true
+	if sameOrder(b, o, inOurs, inTheirs) && !sameOrder(b, t, inOurs, inTheirs) {
+		skeleton, other, otherSet, skeletonIsOurs = t, o, inOurs, false
+	}
+	otherCmds := make(map[string]data.Command)
+	for _, k := range other {
+		otherCmds[k.key] = k.cmd
+	}
+
+	var result []keyed
+	present := make(map[string]bool)
+	for _, k := range skeleton {
+		if inBase[k.key] && !otherSet[k.key] {
+			continue // deleted on the other side
+		}
+		c := k.cmd
+		if orig, ok := baseCmds[k.key]; ok {
+			if oc, ok := otherCmds[k.key]; ok {
+				c = mergeFields(orig, c, oc, skeletonIsOurs)
+			}
+		}
+		result = append(result, keyed{k.key, c})
+		present[k.key] = true
+	}
+
+	for i, k := range other {
+		if inBase[k.key] || present[k.key] {
+			continue
+		}
+		// Insert after the nearest preceding command that made it into the result.
+		at := 0
+		for j := i - 1; j >= 0; j-- {
+			if idx := slices.IndexFunc(result, func(r keyed) bool { return r.key == other[j].key }); idx >= 0 {
+				at = idx + 1
+				break
+			}
+		}
+		result = slices.Insert(result, at, k)
+		present[k.key] = true
+	}
+
+	merged := make([]data.Command, 0, len(result))
+	for _, k := range result {
+		merged = append(merged, k.cmd)
+	}
+	return merged
+}
+
+// mergeFields combines metadata changes to a command present on all sides.
+// a is the skeleton's version and b the other side's; oursFirst says
+// whether a is ours, which wins when both changed a field.
+func mergeFields(base, a, b data.Command, oursFirst bool) data.Command {
+	ours, theirs := a, b
+	if !oursFirst {
+		ours, theirs = b, a
+	}
+	c := ours
+	if ours.Description == base.Description {
+		c.Description = theirs.Description
+	}
+	if slices.Equal(ours.Tags, base.Tags) {
+		c.Tags = theirs.Tags
+	}
+	return c
+}
+
+// sameOrder reports whether side keeps the base order of the commands that
+// survive on both sides.
+func sameOrder(base, side []keyed, inOurs, inTheirs map[string]bool) bool {
+	var want, got []string
+	for _, k := range base {
+		if inOurs[k.key] && inTheirs[k.key] {
+			want = append(want, k.key)
+		}
+	}
+	inBase := keySet(base)
+	for _, k := range side {
+		if inBase[k.key] && inOurs[k.key] && inTheirs[k.key] {
+			got = append(got, k.key)
+		}
+	}
+	return slices.Equal(want, got)
+}
+
+// withKeys identifies each command by its text (ciphertext when private)
+// and occurrence, so duplicates in a stack stay distinct.
+func withKeys(cmds []data.Command) []keyed {
+	seen := make(map[string]int)
+	out := make([]keyed, 0, len(cmds))
+	for _, c := range cmds {
+		id := "cmd:" + c.Cmd
+		if c.Encrypted != "" {
+			id = "enc:" + c.Encrypted
+		}
+		seen[id]++
+		out = append(out, keyed{id + "#" + strconv.Itoa(seen[id]), c})
+	}
+	return out
+}
+
+func keySet(ks []keyed) map[string]bool {
+	set := make(map[string]bool, len(ks))
+	for _, k := range ks {
+		set[k.key] = true
+	}
+	return set
+}
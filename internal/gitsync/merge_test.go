@@ -0,0 +1,152 @@
+package gitsync
+
+import (
+	"reflect"
+	"testing"
+
+	"cam/internal/data"
+)
+
+func cmd(text string) data.Command {
+	return data.Command{Cmd: text}
+}
+
+func described(text, description string, tags ...string) data.Command {
+	return data.Command{Cmd: text, Description: description, Tags: tags}
+}
+
+func private(ciphertext, description string) data.Command {
+	return data.Command{Encrypted: ciphertext, Description: description, IsPrivate: true}
+}
+
+type stacks = map[string][]data.Command
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs stacks
+		want               stacks
+	}{
+		{
+			name:   "concurrent adds keep both",
+			base:   stacks{"git": {cmd("a")}},
+			ours:   stacks{"git": {cmd("x"), cmd("a")}},
+			theirs: stacks{"git": {cmd("y"), cmd("a")}},
+			want:   stacks{"git": {cmd("y"), cmd("x"), cmd("a")}},
+		},
+		{
+			name:   "adds are placed after their predecessor",
+			base:   stacks{"git": {cmd("a"), cmd("b")}},
+			ours:   stacks{"git": {cmd("a"), cmd("b"), cmd("x")}},
+			theirs: stacks{"git": {cmd("a"), cmd("y"), cmd("b")}},
+			want:   stacks{"git": {cmd("a"), cmd("y"), cmd("b"), cmd("x")}},
+		},
+		{
+			name:   "same add on both sides is kept once",
+			base:   stacks{"git": {}},
+			ours:   stacks{"git": {cmd("x")}},
+			theirs: stacks{"git": {cmd("x")}},
+			want:   stacks{"git": {cmd("x")}},
+		},
+		{
+			name:   "delete wins over edit",
+			base:   stacks{"git": {described("a", "old"), cmd("b")}},
+			ours:   stacks{"git": {cmd("b")}},
+			theirs: stacks{"git": {described("a", "new"), cmd("b")}},
+			want:   stacks{"git": {cmd("b")}},
+		},
+		{
+			name:   "edit loses to delete on the other side",
+			base:   stacks{"git": {described("a", "old"), cmd("b")}},
+			ours:   stacks{"git": {described("a", "new"), cmd("b")}},
+			theirs: stacks{"git": {cmd("b")}},
+			want:   stacks{"git": {cmd("b")}},
+		},
+		{
+			name:   "deleted and re-added survives",
+			base:   stacks{"git": {cmd("a"), cmd("b")}},
+			ours:   stacks{"git": {cmd("b")}},
+			theirs: stacks{"git": {cmd("b"), cmd("a"), cmd("a")}},
+			want:   stacks{"git": {cmd("b"), cmd("a")}},
+		},
+		{
+			name:   "edits to different fields combine",
+			base:   stacks{"git": {described("a", "old", "t1")}},
+			ours:   stacks{"git": {described("a", "ours", "t1")}},
+			theirs: stacks{"git": {described("a", "old", "t2")}},
+			want:   stacks{"git": {described("a", "ours", "t2")}},
+		},
+		{
+			name:   "ours wins when both edit a field",
+			base:   stacks{"git": {described("a", "old")}},
+			ours:   stacks{"git": {described("a", "ours")}},
+			theirs: stacks{"git": {described("a", "theirs")}},
+			want:   stacks{"git": {described("a", "ours")}},
+		},
+		{
+			name:   "reorder on their side only",
+			base:   stacks{"git": {cmd("a"), cmd("b"), cmd("c")}},
+			ours:   stacks{"git": {cmd("x"), cmd("a"), cmd("b"), cmd("c")}},
+			theirs: stacks{"git": {cmd("c"), cmd("a"), cmd("b")}},
+			want:   stacks{"git": {cmd("x"), cmd("c"), cmd("a"), cmd("b")}},
+		},
+		{
+			name:   "reorder on both sides keeps ours",
+			base:   stacks{"git": {cmd("a"), cmd("b"), cmd("c")}},
+			ours:   stacks{"git": {cmd("b"), cmd("a"), cmd("c")}},
+			theirs: stacks{"git": {cmd("c"), cmd("b"), cmd("a")}},
+			want:   stacks{"git": {cmd("b"), cmd("a"), cmd("c")}},
+		},
+		{
+			name:   "duplicates are matched by occurrence",
+			base:   stacks{"git": {cmd("a"), cmd("a")}},
+			ours:   stacks{"git": {cmd("a")}},
+			theirs: stacks{"git": {cmd("a"), cmd("a"), cmd("b")}},
+			want:   stacks{"git": {cmd("a"), cmd("b")}},
+		},
+		{
+			name:   "private commands are matched by ciphertext",
+			base:   stacks{"ssh": {private("E1", "old")}},
+			ours:   stacks{"ssh": {private("E1", "new")}},
+			theirs: stacks{"ssh": {private("E1", "old"), private("E2", "")}},
+			want:   stacks{"ssh": {private("E1", "new"), private("E2", "")}},
+		},
+		{
+			name:   "re-encrypted private command counts as a new one",
+			base:   stacks{"ssh": {private("E1", "")}},
+			ours:   stacks{"ssh": {private("E1", "")}},
+			theirs: stacks{"ssh": {private("E9", "")}},
+			want:   stacks{"ssh": {private("E9", "")}},
+		},
+		{
+			name:   "stack deleted on one side keeps the other side's additions",
+			base:   stacks{"old": {cmd("a")}, "gone": {cmd("b")}},
+			ours:   stacks{},
+			theirs: stacks{"old": {cmd("a"), cmd("new")}, "gone": {cmd("b")}},
+			want:   stacks{"old": {cmd("new")}},
+		},
+		{
+			name:   "new stacks on both sides",
+			base:   stacks{},
+			ours:   stacks{"a": {cmd("x")}},
+			theirs: stacks{"b": {cmd("y")}},
+			want:   stacks{"a": {cmd("x")}, "b": {cmd("y")}},
+		},
+		{
+			name:   "emptied stack stays when both sides have it",
+			base:   stacks{"git": {cmd("a")}},
+			ours:   stacks{"git": {}},
+			theirs: stacks{"git": {cmd("a")}},
+			want:   stacks{"git": {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Merge(tt.base, tt.ours, tt.theirs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package history
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a single command recorded in a shell history file.
+type Entry struct {
+	Command string
+	Time    time.Time // zero when the history format has no timestamps
+}
+
+// DetectShell returns the basename of the user's login shell, defaulting to bash.
+func DetectShell() string {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	switch shell {
+	case "bash", "zsh", "fish":
+		return shell
+	}
+	return "bash"
+}
+
+// DefaultPath returns the usual history file location for shell.
+func DefaultPath(shell string) (string, error) {
+	if histFile := os.Getenv("HISTFILE"); histFile != "" && shell == DetectShell() && shell != "fish" {
+		return histFile, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find home directory: %w", err)
+	}
+
+	switch shell {
+	case "bash":
+		return filepath.Join(home, ".bash_history"), nil
+	case "zsh":
+		return filepath.Join(home, ".zsh_history"), nil
+	case "fish":
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		return filepath.Join(dataHome, "fish", "fish_history"), nil
+	}
+	return "", fmt.Errorf("unsupported shell '%s' (supported: bash, zsh, fish)", shell)
+}
+
+// Load reads the default history file for shell, oldest entry first.
+func Load(shell string) ([]Entry, error) {
+	path, err := DefaultPath(shell)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(shell, path)
+}
+
+// LoadFile parses a history file in the format used by shell, oldest entry first.
+func LoadFile(shell string, path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	switch shell {
+	case "bash":
+		return parseBash(scanner), scanner.Err()
+	case "zsh":
+		return parseZsh(scanner), scanner.Err()
+	case "fish":
+		return parseFish(scanner), scanner.Err()
+	}
+	return nil, fmt.Errorf("unsupported shell '%s' (supported: bash, zsh, fish)", shell)
+}
+
+// Last returns the final n entries of entries.
+func Last(entries []Entry, n int) []Entry {
+	if n <= 0 || len(entries) <= n {
+		return entries
+	}
+	return entries[len(entries)-n:]
+}
+
+// parseBash handles plain bash history, including "#<epoch>" lines written with HISTTIMEFORMAT.
+func parseBash(scanner *bufio.Scanner) []Entry {
+	var entries []Entry
+	var ts time.Time
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			if sec, err := strconv.ParseInt(line[1:], 10, 64); err == nil {
+				ts = time.Unix(sec, 0)
+				continue
+			}
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entries = append(entries, Entry{Command: line, Time: ts})
+		ts = time.Time{}
+	}
+	return entries
+}
+
+// parseZsh handles both plain and extended (": <epoch>:<duration>;cmd") zsh history.
+func parseZsh(scanner *bufio.Scanner) []Entry {
+	var entries []Entry
+	var current *Entry
+	continued := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if continued && current != nil {
+			current.Command += "\n" + strings.TrimSuffix(line, "\\")
+			continued = strings.HasSuffix(line, "\\")
+			continue
+		}
+
+		entry := Entry{Command: line}
+		if strings.HasPrefix(line, ": ") {
+			if meta, cmd, ok := strings.Cut(line[2:], ";"); ok {
+				stamp, _, _ := strings.Cut(meta, ":")
+				if sec, err := strconv.ParseInt(stamp, 10, 64); err == nil {
+					entry = Entry{Command: cmd, Time: time.Unix(sec, 0)}
+				}
+			}
+		}
+
+		continued = strings.HasSuffix(entry.Command, "\\")
+		entry.Command = strings.TrimSuffix(entry.Command, "\\")
+		if strings.TrimSpace(entry.Command) == "" && !continued {
+			continue
+		}
+		entries = append(entries, entry)
+		current = &entries[len(entries)-1]
+	}
+	return entries
+}
+
+// fishUnescape decodes fish's escaping of newlines and backslashes in one
+// pass, so an escaped backslash followed by "n" stays as it was typed.
+var fishUnescape = strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+
+// parseFish handles fish's YAML-like history ("- cmd: ..." followed by "  when: <epoch>").
+func parseFish(scanner *bufio.Scanner) []Entry {
+	var entries []Entry
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "- cmd: "):
+			cmd := fishUnescape.Replace(strings.TrimPrefix(line, "- cmd: "))
+			entries = append(entries, Entry{Command: cmd})
+		case strings.HasPrefix(line, "  when: ") && len(entries) > 0:
+			if sec, err := strconv.ParseInt(strings.TrimPrefix(line, "  when: "), 10, 64); err == nil {
+				entries[len(entries)-1].Time = time.Unix(sec, 0)
+			}
+		}
+	}
+	return entries
+}
@@ -0,0 +1,30 @@
+package history
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFish(t *testing.T) {
+	input := `- cmd: echo one\ntwo
+  when: 1700000000
+- cmd: printf 'a\\nb'
+  when: 1700000060
+  paths:
+    - a
+- cmd: echo C:\\\\share
+`
+	want := []Entry{
+		{Command: "echo one\ntwo", Time: time.Unix(1700000000, 0)},
+		{Command: `printf 'a\nb'`, Time: time.Unix(1700000060, 0)},
+		{Command: `echo C:\\share`},
+	}
+
+	got := parseFish(bufio.NewScanner(strings.NewReader(input)))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFish =\n%#v\nwant\n%#v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ranked is a distinct command with how often and when it was last run.
+type Ranked struct {
+	Command string
+	Count   int
+	Last    time.Time // zero when the history has no timestamps
+}
+
+// Rank groups entries by command (ignoring surrounding whitespace) and sorts
+// them by frequency, then by most recent use.
+func Rank(entries []Entry) []Ranked {
+	index := make(map[string]int)
+	var ranked []Ranked
+	for _, e := range entries {
+		cmd := strings.TrimSpace(e.Command)
+		if cmd == "" {
+			continue
+		}
+		i, ok := index[cmd]
+		if !ok {
+			i = len(ranked)
+			index[cmd] = i
+			ranked = append(ranked, Ranked{Command: cmd})
+		}
+		ranked[i].Count++
+		if e.Time.After(ranked[i].Last) {
+			ranked[i].Last = e.Time
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Last.After(ranked[j].Last)
+	})
+	return ranked
+}
+
+// Since returns the entries recorded at or after t. Entries without a
+// timestamp are dropped.
+func Since(entries []Entry, t time.Time) []Entry {
+	var recent []Entry
+	for _, e := range entries {
+		if !e.Time.IsZero() && !e.Time.Before(t) {
+			recent = append(recent, e)
+		}
+	}
+	return recent
+}
@@ -0,0 +1,97 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RecordFile is written by the shell hooks after every command:
+	// the exit code on the first line, the command on the rest.
+	RecordFile = "last_command"
+	// StderrFile is written by `cam wrap`: the command as a quoted Go string
+	// on the first line (so multi-line commands fit), its captured stderr on
+	// the rest. It is removed when a wrapped command writes no stderr, and
+	// by the shell hooks after any command that isn't wrapped.
+	StderrFile = "last_stderr"
+
+	wrapPrefix = "cam wrap -- "
+)
+
+// Record is the last command run in an integrated shell.
+type Record struct {
+	Command  string
+	ExitCode int
+	Stderr   string // empty unless the command was run through `cam wrap`
+	Time     time.Time
+}
+
+// ReadRecord loads the last command recorded by the shell hooks in dir,
+// attaching stderr captured by `cam wrap` when it belongs to the same command.
+func ReadRecord(dir string) (*Record, error) {
+	path := filepath.Join(dir, RecordFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	codeLine, command, ok := strings.Cut(strings.TrimRight(string(content), "\n"), "\n")
+	if !ok {
+		return nil, fmt.Errorf("malformed record in %s", path)
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(codeLine))
+	if err != nil {
+		return nil, fmt.Errorf("malformed exit code in %s: %w", path, err)
+	}
+
+	rec := &Record{
+		Command:  strings.TrimPrefix(command, wrapPrefix),
+		ExitCode: code,
+	}
+	if info, err := os.Stat(path); err == nil {
+		rec.Time = info.ModTime()
+	}
+
+	if captured, err := os.ReadFile(filepath.Join(dir, StderrFile)); err == nil {
+		wrapped, stderr, _ := strings.Cut(string(captured), "\n")
+		if unquoted, err := strconv.Unquote(wrapped); err == nil {
+			wrapped = unquoted
+		}
+		if wrapped == rec.Command {
+			rec.Stderr = stderr
+		}
+	}
+
+	return rec, nil
+}
+
+// WriteRecord stores rec in dir in the format the shell hooks use.
+func WriteRecord(dir string, rec Record) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	content := fmt.Sprintf("%d\n%s\n", rec.ExitCode, rec.Command)
+	if err := os.WriteFile(filepath.Join(dir, RecordFile), []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write command record: %w", err)
+	}
+
+	// A capture from an earlier run of the same command must not be
+	// attached to this one.
+	stderrPath := filepath.Join(dir, StderrFile)
+	if rec.Stderr == "" {
+		if err := os.Remove(stderrPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove captured stderr: %w", err)
+		}
+		return nil
+	}
+	captured := strconv.Quote(rec.Command) + "\n" + rec.Stderr
+	if err := os.WriteFile(stderrPath, []byte(captured), 0600); err != nil {
+		return fmt.Errorf("failed to write captured stderr: %w", err)
+	}
+	return nil
+}
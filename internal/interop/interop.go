@@ -0,0 +1,223 @@
+// Package interop converts cam stacks to and from the formats of other
+// snippet managers (pet, navi, tldr pages and Warp workflows).
+package interop
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Snippet is a command in a format-neutral form. Parameters use cam's
+// placeholder syntax, <name> or <name=default>.
+type Snippet struct {
+	Stack       string // empty when the source has no grouping
+	Command     string
+	Description string
+	Tags        []string
+}
+
+// Format reads and writes one external snippet format.
+type Format struct {
+	Name        string
+	Description string
+	// Exts are the file extensions read when importing a directory; the
+	// first one is used when exporting to a directory.
+	Exts []string
+	// PerSnippet formats hold a single snippet per file when exported to a
+	// directory, the others one file per stack.
+	PerSnippet bool
+	// StackSource says where imported snippets get their stack from.
+	StackSource string
+	// DefaultPath is where the tool keeps its snippets, if it has a fixed place.
+	DefaultPath func() (string, error)
+	Parse       func(r io.Reader) ([]Snippet, error)
+	Write       func(w io.Writer, snippets []Snippet) error
+}
+
+var formats = []Format{petFormat, naviFormat, tldrFormat, warpFormat}
+
+// Formats returns the supported formats.
+func Formats() []Format {
+	return formats
+}
+
+// Names returns the names of the supported formats.
+func Names() []string {
+	var names []string
+	for _, f := range formats {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Lookup returns the format called name.
+func Lookup(name string) (Format, error) {
+	for _, f := range formats {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return Format{}, fmt.Errorf("unknown format '%s' (supported: %s)", name, strings.Join(Names(), ", "))
+}
+
+// ReadFiles parses every path, expanding directories to the files with one
+// of the format's extensions.
+func (f Format) ReadFiles(paths []string) ([]Snippet, error) {
+	var snippets []Snippet
+	for _, path := range paths {
+		files, err := f.expand(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			parsed, err := f.readFile(file)
+			if err != nil {
+				return nil, err
+			}
+			snippets = append(snippets, parsed...)
+		}
+	}
+	return snippets, nil
+}
+
+func (f Format) readFile(path string) ([]Snippet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	snippets, err := f.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return snippets, nil
+}
+
+func (f Format) expand(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && slices.Contains(f.Exts, filepath.Ext(p)) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s files (%s) found in %s", f.Name, strings.Join(f.Exts, ", "), path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// WriteDir writes snippets into dir, one file per stack or per snippet.
+// It returns the files written.
+func (f Format) WriteDir(dir string, snippets []Snippet) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var groups [][]Snippet
+	var names []string
+	if f.PerSnippet {
+		seen := make(map[string]int)
+		for _, s := range snippets {
+			seen[s.Stack]++
+			groups = append(groups, []Snippet{s})
+			names = append(names, fmt.Sprintf("%s-%d", fileName(s.Stack), seen[s.Stack]))
+		}
+	} else {
+		for _, group := range GroupByStack(snippets) {
+			groups = append(groups, group)
+			names = append(names, fileName(group[0].Stack))
+		}
+	}
+
+	var written []string
+	for i, group := range groups {
+		path := filepath.Join(dir, names[i]+f.Exts[0])
+		file, err := os.Create(path)
+		if err != nil {
+			return written, fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		err = f.Write(file, group)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// GroupByStack splits snippets into runs sharing a stack, keeping their order.
+func GroupByStack(snippets []Snippet) [][]Snippet {
+	var groups [][]Snippet
+	index := make(map[string]int)
+	for _, s := range snippets {
+		i, ok := index[s.Stack]
+		if !ok {
+			i = len(groups)
+			index[s.Stack] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], s)
+	}
+	return groups
+}
+
+// fileName turns a stack name into something safe to use as a file name.
+func fileName(stack string) string {
+	if stack == "" {
+		return "cam"
+	}
+	return strings.NewReplacer("/", "-", "\\", "-", ":", "-", " ", "-").Replace(stack)
+}
+
+// stackName turns a tag or title from another tool into a stack name.
+func stackName(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), "-")
+}
+
+// splitStack uses the first tag as the stack, for formats without stacks.
+// Export writes the stack as the first tag so the two round-trip.
+func splitStack(tags []string) (string, []string) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+	return stackName(tags[0]), tags[1:]
+}
+
+// joinStack is the inverse of splitStack.
+func joinStack(s Snippet) []string {
+	tags := []string{}
+	if s.Stack != "" {
+		tags = append(tags, s.Stack)
+	}
+	for _, t := range s.Tags {
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
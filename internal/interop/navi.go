@@ -0,0 +1,117 @@
+package interop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// navi (github.com/denisidoro/navi) cheatsheets group snippets under a
+// "% tag, tag" header, with a "# description" line before each command:
+//
+//	% git, vcs
+//
+//	# Undo the last commit
+//	git reset --soft HEAD~1
+//
+// The first header tag is used as the stack. Variable definitions ("$ name: ...")
+// have no cam equivalent and are skipped; the <name> placeholders are kept.
+var naviFormat = Format{
+	Name:        "navi",
+	Description: "navi cheatsheets (.cheat)",
+	StackSource: "the first tag of each % header",
+	Exts:        []string{".cheat"},
+	DefaultPath: func() (string, error) {
+		dir := os.Getenv("XDG_DATA_HOME")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(home, ".local", "share")
+		}
+		return filepath.Join(dir, "navi", "cheats"), nil
+	},
+	Parse: parseNavi,
+	Write: writeNavi,
+}
+
+func parseNavi(r io.Reader) ([]Snippet, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var snippets []Snippet
+	var tags []string
+	var description string
+	var lines []string
+
+	flush := func() {
+		if len(lines) > 0 {
+			stack, rest := splitStack(tags)
+			snippets = append(snippets, Snippet{
+				Stack:       stack,
+				Command:     strings.Join(lines, "\n"),
+				Description: description,
+				Tags:        slices.Clone(rest),
+			})
+			description = ""
+		}
+		lines = nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "%"):
+			flush()
+			tags = nil
+			for _, t := range strings.Split(trimmed[1:], ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					tags = append(tags, t)
+				}
+			}
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			description = strings.TrimSpace(trimmed[1:])
+		case strings.HasPrefix(trimmed, ";"), strings.HasPrefix(trimmed, "$"), strings.HasPrefix(trimmed, "@"):
+			// Comments, variable definitions and includes.
+			flush()
+		default:
+			lines = append(lines, line)
+		}
+	}
+	flush()
+	return snippets, scanner.Err()
+}
+
+func writeNavi(w io.Writer, snippets []Snippet) error {
+	bw := bufio.NewWriter(w)
+	header := ""
+	for _, s := range snippets {
+		if h := "% " + strings.Join(joinStack(s), ", "); h != header {
+			if header != "" {
+				bw.WriteString("\n")
+			}
+			header = h
+			fmt.Fprintf(bw, "%s\n", header)
+		}
+		bw.WriteString("\n")
+		if s.Description != "" {
+			fmt.Fprintf(bw, "# %s\n", singleLine(s.Description))
+		}
+		fmt.Fprintf(bw, "%s\n", s.Command)
+	}
+	return bw.Flush()
+}
+
+// singleLine joins a possibly multi-line text into one line.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
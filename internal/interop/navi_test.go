@@ -0,0 +1,61 @@
+package interop
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNavi(t *testing.T) {
+	input := `% git, code
+
+# Change branch
+git checkout <branch>
+
+$ branch: git branch | awk '{print $NF}'
+
+; a comment
+# Build and run
+docker build -t <image> . \
+  && docker run --rm <image>
+
+% Docker Compose
+
+git status
+`
+	want := []Snippet{
+		{Stack: "git", Command: "git checkout <branch>", Description: "Change branch", Tags: []string{"code"}},
+		{Stack: "git", Command: "docker build -t <image> . \\\n  && docker run --rm <image>", Description: "Build and run", Tags: []string{"code"}},
+		{Stack: "docker-compose", Command: "git status", Tags: []string{}},
+	}
+
+	got, err := parseNavi(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseNavi: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNavi =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestNaviRoundTrip(t *testing.T) {
+	snippets := []Snippet{
+		{Stack: "k8s", Command: "kubectl get pods -n <ns=default>", Description: "List pods", Tags: []string{"kubectl"}},
+		{Stack: "k8s", Command: "kubectl logs -f <pod>", Description: "Follow\nlogs", Tags: []string{"kubectl"}},
+		{Stack: "net", Command: "ss -tlnp", Tags: []string{}},
+	}
+
+	var b strings.Builder
+	if err := writeNavi(&b, snippets); err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseNavi(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snippets[1].Description = "Follow logs"
+	if !reflect.DeepEqual(got, snippets) {
+		t.Errorf("round trip =\n%#v\nwant\n%#v\n%s", got, snippets, b.String())
+	}
+}
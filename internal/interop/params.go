@@ -0,0 +1,57 @@
+package interop
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Param is a placeholder in a command, written <name> or <name=default>.
+type Param struct {
+	Name    string
+	Default string
+}
+
+var (
+	camParam   = regexp.MustCompile(`<([A-Za-z_][\w./-]*)(?:=([^<>\n]*))?>`)
+	braceParam = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+)
+
+// Params lists the distinct placeholders in command, in order of appearance.
+func Params(command string) []Param {
+	var params []Param
+	seen := make(map[string]bool)
+	for _, m := range camParam.FindAllStringSubmatch(command, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		params = append(params, Param{Name: m[1], Default: m[2]})
+	}
+	return params
+}
+
+// fromBraces converts {{name}} placeholders (tldr, Warp) to cam's <name>,
+// adding defaults where known.
+func fromBraces(command string, defaults map[string]string) string {
+	return braceParam.ReplaceAllStringFunc(command, func(m string) string {
+		name := braceParam.FindStringSubmatch(m)[1]
+		name = strings.Join(strings.Fields(name), "_")
+		if def := defaults[name]; def != "" {
+			return "<" + name + "=" + def + ">"
+		}
+		return "<" + name + ">"
+	})
+}
+
+// toBraces converts cam placeholders to {{name}}, dropping defaults.
+func toBraces(command string) string {
+	return camParam.ReplaceAllString(command, "{{$1}}")
+}
+
+// ReplaceParams replaces every placeholder in command with the result of repl.
+func ReplaceParams(command string, repl func(p Param) string) string {
+	return camParam.ReplaceAllStringFunc(command, func(m string) string {
+		sub := camParam.FindStringSubmatch(m)
+		return repl(Param{Name: sub[1], Default: sub[2]})
+	})
+}
@@ -0,0 +1,352 @@
+package interop
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// pet (github.com/knqyf263/pet) keeps snippets in a TOML file:
+//
+//	[[snippets]]
+//	  description = "Show expiration date of SSL certificate"
+//	  command = "echo | openssl s_client -connect <host=example.com>:443"
+//	  tag = ["network", "ssl"]
+//	  output = ""
+//
+// pet has no stacks, so the first tag is used as the stack.
+var petFormat = Format{
+	Name:        "pet",
+	Description: "pet snippets (TOML)",
+	StackSource: "the first tag of each snippet, or 'pet' if it has none",
+	Exts:        []string{".toml"},
+	DefaultPath: func() (string, error) {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "pet", "snippet.toml"), nil
+	},
+	Parse: parsePet,
+	Write: writePet,
+}
+
+var errUnterminated = errors.New("unterminated value")
+
+func parsePet(r io.Reader) ([]Snippet, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var snippets []Snippet
+	var current *Snippet
+	inSnippet := false
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inSnippet = line == "[[snippets]]"
+			if inSnippet {
+				snippets = append(snippets, Snippet{})
+				current = &snippets[len(snippets)-1]
+			}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNum)
+		}
+		start := lineNum
+
+		// Multi-line strings and arrays continue until the value is complete.
+		raw = strings.TrimSpace(raw)
+		value, err := parseTOMLValue(raw)
+		for errors.Is(err, errUnterminated) && scanner.Scan() {
+			lineNum++
+			raw += "\n" + scanner.Text()
+			value, err = parseTOMLValue(raw)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+		if !inSnippet {
+			continue
+		}
+
+		switch strings.Trim(strings.TrimSpace(key), `"'`) {
+		case "command":
+			current.Command, _ = value.(string)
+		case "description":
+			current.Description, _ = value.(string)
+		case "tag":
+			tags, _ := value.([]string)
+			current.Stack, current.Tags = splitStack(tags)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []Snippet
+	for _, s := range snippets {
+		if strings.TrimSpace(s.Command) != "" {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// parseTOMLValue parses the strings and string arrays pet uses. Other
+// values (numbers, booleans) are returned as their text.
+func parseTOMLValue(s string) (any, error) {
+	value, rest, err := parseTOMLItem(s)
+	if err != nil {
+		return nil, err
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return nil, fmt.Errorf("unexpected text after value: %s", rest)
+	}
+	return value, nil
+}
+
+func parseTOMLItem(s string) (any, string, error) {
+	s = strings.TrimLeft(s, " \t")
+	switch {
+	case strings.HasPrefix(s, `"""`):
+		end := strings.Index(s[3:], `"""`)
+		for end >= 0 && escaped(s[3:], end) {
+			next := strings.Index(s[3+end+1:], `"""`)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += next + 1
+		}
+		if end < 0 {
+			return nil, "", errUnterminated
+		}
+		body := strings.TrimPrefix(s[3:3+end], "\n")
+		body = lineContinuation(body)
+		value, err := unescapeTOML(body)
+		return value, s[3+end+3:], err
+	case strings.HasPrefix(s, `'''`):
+		end := strings.Index(s[3:], `'''`)
+		if end < 0 {
+			return nil, "", errUnterminated
+		}
+		return strings.TrimPrefix(s[3:3+end], "\n"), s[3+end+3:], nil
+	case strings.HasPrefix(s, `"`):
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '\n':
+				return nil, "", errors.New("newline in string")
+			case '"':
+				value, err := unescapeTOML(s[1:i])
+				return value, s[i+1:], err
+			}
+		}
+		return nil, "", errors.New("unterminated string")
+	case strings.HasPrefix(s, "'"):
+		end := strings.IndexAny(s[1:], "'\n")
+		if end < 0 || s[1+end] == '\n' {
+			return nil, "", errors.New("unterminated string")
+		}
+		return s[1 : 1+end], s[1+end+1:], nil
+	case strings.HasPrefix(s, "["):
+		return parseTOMLArray(s[1:])
+	}
+
+	end := strings.IndexAny(s, ",]#\n")
+	if end < 0 {
+		end = len(s)
+	}
+	return strings.TrimSpace(s[:end]), s[end:], nil
+}
+
+func parseTOMLArray(s string) (any, string, error) {
+	var items []string
+	for {
+		s = skipTOMLSpace(s)
+		if s == "" {
+			return nil, "", errUnterminated
+		}
+		if s[0] == ']' {
+			return items, s[1:], nil
+		}
+
+		value, rest, err := parseTOMLItem(s)
+		if err != nil {
+			return nil, "", err
+		}
+		if str, ok := value.(string); ok {
+			items = append(items, str)
+		}
+
+		s = skipTOMLSpace(rest)
+		switch {
+		case s == "":
+			return nil, "", errUnterminated
+		case s[0] == ',':
+			s = s[1:]
+		case s[0] != ']':
+			return nil, "", fmt.Errorf("expected ',' or ']' in array, found %q", s[0])
+		}
+	}
+}
+
+// skipTOMLSpace skips whitespace, newlines and comments between array items.
+func skipTOMLSpace(s string) string {
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if !strings.HasPrefix(s, "#") {
+			return s
+		}
+		if _, rest, ok := strings.Cut(s, "\n"); ok {
+			s = rest
+		} else {
+			return ""
+		}
+	}
+}
+
+// escaped reports whether the byte at i in s is preceded by an odd number of backslashes.
+func escaped(s string, i int) bool {
+	n := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// lineContinuation removes a backslash at the end of a line along with the
+// following whitespace, as in TOML multi-line basic strings.
+func lineContinuation(s string) string {
+	lines := strings.Split(s, "\n")
+	var b strings.Builder
+	trimNext := false
+	for i, line := range lines {
+		if trimNext {
+			line = strings.TrimLeft(line, " \t")
+		}
+		trimmed := strings.TrimRight(line, " \t")
+		trimNext = strings.HasSuffix(trimmed, `\`) && !escaped(trimmed, len(trimmed)-1) && i < len(lines)-1
+		if trimNext {
+			b.WriteString(trimmed[:len(trimmed)-1])
+			continue
+		}
+		b.WriteString(line)
+		if i < len(lines)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func unescapeTOML(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", errors.New("trailing backslash in string")
+		}
+		switch s[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case 'e':
+			b.WriteByte(0x1b)
+		case '"', '\\':
+			b.WriteByte(s[i])
+		case 'u', 'U':
+			size := 4
+			if s[i] == 'U' {
+				size = 8
+			}
+			if i+size >= len(s) {
+				return "", errors.New("short unicode escape in string")
+			}
+			code, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid unicode escape \\%c%s", s[i], s[i+1:i+1+size])
+			}
+			b.WriteRune(rune(code))
+			i += size
+		default:
+			return "", fmt.Errorf("invalid escape \\%c in string", s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// quoteTOML writes s as a TOML basic string.
+func quoteTOML(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func writePet(w io.Writer, snippets []Snippet) error {
+	bw := bufio.NewWriter(w)
+	for i, s := range snippets {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		var tags []string
+		for _, t := range joinStack(s) {
+			tags = append(tags, quoteTOML(t))
+		}
+		fmt.Fprintf(bw, "[[snippets]]\n")
+		fmt.Fprintf(bw, "  description = %s\n", quoteTOML(s.Description))
+		fmt.Fprintf(bw, "  command = %s\n", quoteTOML(s.Command))
+		fmt.Fprintf(bw, "  tag = [%s]\n", strings.Join(tags, ", "))
+		fmt.Fprintf(bw, "  output = \"\"\n")
+	}
+	return bw.Flush()
+}
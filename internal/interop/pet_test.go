@@ -0,0 +1,138 @@
+package interop
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePet(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Snippet
+	}{
+		{
+			name: "pet snippet file",
+			input: `[[snippets]]
+  description = "ping"
+  command = "ping 8.8.8.8"
+  tag = ["network", "google"]
+  output = ""
+
+[[snippets]]
+  description = "Show expiration date of SSL certificate"
+  command = "echo | openssl s_client -connect <host=example.com>:443 2>/dev/null | openssl x509 -dates -noout"
+  tag = []
+  output = ""
+`,
+			want: []Snippet{
+				{Stack: "network", Command: "ping 8.8.8.8", Description: "ping", Tags: []string{"google"}},
+				{Command: "echo | openssl s_client -connect <host=example.com>:443 2>/dev/null | openssl x509 -dates -noout", Description: "Show expiration date of SSL certificate"},
+			},
+		},
+		{
+			name: "escapes",
+			input: `[[snippets]]
+  description = "quote \"it\"\ttab \u00e9"
+  command = "printf '%s\\n' \"$HOME\""
+  tag = ["Shell Tricks"]
+`,
+			want: []Snippet{
+				{Stack: "shell-tricks", Command: `printf '%s\n' "$HOME"`, Description: "quote \"it\"\ttab é", Tags: []string{}},
+			},
+		},
+		{
+			name: "multi-line strings",
+			input: `[[snippets]]
+  description = 'literal \n stays'
+  command = """
+docker run \
+    --rm alpine
+echo "done" """
+  tag = [
+    "docker", # runtime
+    "ops",
+  ]
+
+[[snippets]]
+  command = '''
+for f in *.txt; do
+  wc -l "$f"
+done'''
+`,
+			want: []Snippet{
+				{Stack: "docker", Command: "docker run --rm alpine\necho \"done\" ", Description: `literal \n stays`, Tags: []string{"ops"}},
+				{Command: "for f in *.txt; do\n  wc -l \"$f\"\ndone"},
+			},
+		},
+		{
+			name: "other tables and empty commands are skipped",
+			input: `# pet config
+[General]
+  editor = "vim"
+
+[[snippets]]
+  description = "nothing"
+  command = ""
+
+[[snippets]]
+  command = "ls"
+`,
+			want: []Snippet{{Command: "ls"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePet(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("parsePet: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePet =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePetErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unterminated string", "[[snippets]]\ncommand = \"ls\n"},
+		{"invalid escape", "[[snippets]]\ncommand = \"\\q\"\n"},
+		{"missing value", "[[snippets]]\ncommand\n"},
+		{"trailing text", "[[snippets]]\ncommand = \"ls\" extra\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parsePet(strings.NewReader(tt.input)); err == nil {
+				t.Error("parsePet succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestPetRoundTrip(t *testing.T) {
+	snippets := []Snippet{
+		{Stack: "git", Command: `git log --format="%h %s" <ref=HEAD>`, Description: "Short log", Tags: []string{"history"}},
+		{Stack: "misc", Command: "echo 'a\\b'\nprintf '\\t\\n'", Description: "Tabs\tand \"quotes\""},
+	}
+
+	var b strings.Builder
+	if err := writePet(&b, snippets); err != nil {
+		t.Fatal(err)
+	}
+	got, err := parsePet(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("parsePet: %v\n%s", err, b.String())
+	}
+
+	snippets[1].Tags = []string{}
+	if !reflect.DeepEqual(got, snippets) {
+		t.Errorf("round trip =\n%#v\nwant\n%#v", got, snippets)
+	}
+}
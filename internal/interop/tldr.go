@@ -0,0 +1,104 @@
+package interop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// tldr pages are markdown: the title names the tool, each example is a
+// "- description:" line followed by the command in backticks, and
+// placeholders are written {{name}}:
+//
+//	# tar
+//
+//	> Archiving utility.
+//
+//	- Extract an archive into a directory:
+//
+//	`tar xf {{source.tar}} -C {{directory}}`
+//
+// The title becomes the stack.
+var tldrFormat = Format{
+	Name:        "tldr",
+	Description: "tldr-style markdown pages",
+	StackSource: "the page title",
+	Exts:        []string{".md"},
+	Parse:       parseTldr,
+	Write:       writeTldr,
+}
+
+func parseTldr(r io.Reader) ([]Snippet, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var snippets []Snippet
+	var stack, description string
+	var code []string // lines of a command spanning several lines
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if code != nil {
+			code = append(code, scanner.Text())
+			if strings.HasSuffix(line, "`") {
+				command := strings.TrimSuffix(strings.Join(code, "\n"), "`")
+				snippets = append(snippets, tldrSnippet(stack, command, description))
+				code, description = nil, ""
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "# "):
+			stack = stackName(line[2:])
+		case strings.HasPrefix(line, "- "):
+			description = strings.TrimSuffix(strings.TrimSpace(line[2:]), ":")
+		case strings.HasPrefix(line, "`"):
+			if len(line) > 1 && strings.HasSuffix(line, "`") {
+				snippets = append(snippets, tldrSnippet(stack, line[1:len(line)-1], description))
+				description = ""
+			} else {
+				code = []string{line[1:]}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if code != nil {
+		return nil, fmt.Errorf("unterminated command after '%s'", description)
+	}
+	return snippets, nil
+}
+
+func tldrSnippet(stack, command, description string) Snippet {
+	return Snippet{
+		Stack:       stack,
+		Command:     fromBraces(command, nil),
+		Description: description,
+	}
+}
+
+func writeTldr(w io.Writer, snippets []Snippet) error {
+	bw := bufio.NewWriter(w)
+	for i, group := range GroupByStack(snippets) {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		title := group[0].Stack
+		if title == "" {
+			title = "cam"
+		}
+		fmt.Fprintf(bw, "# %s\n\n", title)
+		fmt.Fprintf(bw, "> Commands from the cam stack %s.\n", title)
+		for _, s := range group {
+			description := singleLine(s.Description)
+			if description == "" {
+				description = "Run " + strings.Fields(s.Command)[0]
+			}
+			fmt.Fprintf(bw, "\n- %s:\n\n`%s`\n", strings.TrimSuffix(description, "."), toBraces(s.Command))
+		}
+	}
+	return bw.Flush()
+}
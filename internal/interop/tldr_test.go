@@ -0,0 +1,57 @@
+package interop
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTldr(t *testing.T) {
+	input := "# tar\n\n" +
+		"> Archiving utility.\n" +
+		"> More information: <https://www.gnu.org/software/tar>.\n\n" +
+		"- [c]reate an archive and write it to a [f]ile:\n\n" +
+		"`tar cf {{path/to/target.tar}} {{path/to/file1 path/to/file2 ...}}`\n\n" +
+		"- E[x]tract a (compressed) archive [f]ile into the current directory [v]erbosely:\n\n" +
+		"`tar xvf {{ path/to/source.tar[.gz|.bz2|.xz] }}`\n\n" +
+		"- Run a script:\n\n" +
+		"`for f in {{*.tar}}; do\n  tar tf \"$f\"\ndone`\n"
+	want := []Snippet{
+		{Stack: "tar", Command: "tar cf <path/to/target.tar> <path/to/file1_path/to/file2_...>", Description: "[c]reate an archive and write it to a [f]ile"},
+		{Stack: "tar", Command: "tar xvf <path/to/source.tar[.gz|.bz2|.xz]>", Description: "E[x]tract a (compressed) archive [f]ile into the current directory [v]erbosely"},
+		{Stack: "tar", Command: "for f in <*.tar>; do\n  tar tf \"$f\"\ndone", Description: "Run a script"},
+	}
+
+	got, err := parseTldr(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseTldr: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTldr =\n%#v\nwant\n%#v", got, want)
+	}
+
+	if _, err := parseTldr(strings.NewReader("# x\n\n- Open:\n\n`echo \\\n")); err == nil {
+		t.Error("parseTldr accepted an unterminated command")
+	}
+}
+
+func TestTldrRoundTrip(t *testing.T) {
+	snippets := []Snippet{
+		{Stack: "ffmpeg", Command: "ffmpeg -i <input> -vn <output.mp3>", Description: "Extract the audio"},
+		{Stack: "ffmpeg", Command: "ffmpeg -i <input>", Description: "Show stream info."},
+	}
+
+	var b strings.Builder
+	if err := writeTldr(&b, snippets); err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseTldr(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snippets[1].Description = "Show stream info"
+	if !reflect.DeepEqual(got, snippets) {
+		t.Errorf("round trip =\n%#v\nwant\n%#v\n%s", got, snippets, b.String())
+	}
+}
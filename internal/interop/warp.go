@@ -0,0 +1,125 @@
+package interop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Warp workflows are YAML documents, one per file, with {{name}} arguments:
+//
+//	name: Remove a Homebrew package and its dependencies
+//	command: brew rmtree {{package_name}}
+//	tags: ["homebrew"]
+//	description: Uses rmtree to remove a package and its unused dependencies
+//	arguments:
+//	  - name: package_name
+//	    default_value: ~
+//
+// The first tag is used as the stack, and argument defaults become
+// <name=default> placeholders.
+var warpFormat = Format{
+	Name:        "warp",
+	Description: "Warp workflows (YAML)",
+	StackSource: "the first tag of each workflow, or 'warp' if it has none",
+	Exts:        []string{".yaml", ".yml"},
+	PerSnippet:  true,
+	Parse:       parseWarp,
+	Write:       writeWarp,
+}
+
+func parseWarp(r io.Reader) ([]Snippet, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := ParseYAML(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var snippets []Snippet
+	for _, doc := range docs {
+		workflow, ok := doc.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected a workflow mapping")
+		}
+		command := strings.TrimRight(yamlField(workflow, "command"), "\n")
+		if strings.TrimSpace(command) == "" {
+			continue
+		}
+
+		defaults := make(map[string]string)
+		args, _ := workflow["arguments"].([]any)
+		for _, a := range args {
+			if arg, ok := a.(map[string]any); ok {
+				defaults[yamlField(arg, "name")] = yamlField(arg, "default_value")
+			}
+		}
+
+		description := yamlField(workflow, "description")
+		if description == "" {
+			description = yamlField(workflow, "name")
+		}
+
+		var tags []string
+		list, _ := workflow["tags"].([]any)
+		for _, t := range list {
+			if tag, ok := t.(string); ok && tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		stack, tags := splitStack(tags)
+
+		snippets = append(snippets, Snippet{
+			Stack:       stack,
+			Command:     fromBraces(command, defaults),
+			Description: singleLine(description),
+			Tags:        tags,
+		})
+	}
+	return snippets, nil
+}
+
+func yamlField(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func writeWarp(w io.Writer, snippets []Snippet) error {
+	bw := bufio.NewWriter(w)
+	for i, s := range snippets {
+		if i > 0 {
+			bw.WriteString("---\n")
+		}
+		name := s.Description
+		if name == "" {
+			name = strings.SplitN(s.Command, "\n", 2)[0]
+		}
+
+		var tags []string
+		for _, t := range joinStack(s) {
+			tags = append(tags, YAMLString(t))
+		}
+
+		fmt.Fprintf(bw, "name: %s\n", YAMLString(singleLine(name)))
+		fmt.Fprintf(bw, "command: %s\n", YAMLBlock(toBraces(s.Command), "  "))
+		fmt.Fprintf(bw, "tags: [%s]\n", strings.Join(tags, ", "))
+		if s.Description != "" {
+			fmt.Fprintf(bw, "description: %s\n", YAMLString(singleLine(s.Description)))
+		}
+		if params := Params(s.Command); len(params) > 0 {
+			bw.WriteString("arguments:\n")
+			for _, p := range params {
+				fmt.Fprintf(bw, "  - name: %s\n", YAMLString(p.Name))
+				if p.Default != "" {
+					fmt.Fprintf(bw, "    default_value: %s\n", YAMLString(p.Default))
+				} else {
+					bw.WriteString("    default_value: ~\n")
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
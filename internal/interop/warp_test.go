@@ -0,0 +1,69 @@
+package interop
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWarp(t *testing.T) {
+	input := `---
+name: Remove a Homebrew package and its dependencies
+command: "brew rmtree {{package_name}}"
+tags: ["homebrew"]
+description: Uses rmtree to remove a package and its unused dependencies
+arguments:
+  - name: package_name
+    description: The name of the package that should be removed
+    default_value: ~
+source_url: "https://stackoverflow.com/questions/7323261"
+author: Ory Band
+shells: []
+---
+name: Kill the process on a port
+command: |-
+  lsof -ti tcp:{{port}} \
+    | xargs kill -9
+tags:
+  - networking
+  - process
+arguments:
+  - name: port
+    default_value: 8080
+---
+name: Untagged
+command: "echo \"\e[1mbold\e[0m\""
+`
+	want := []Snippet{
+		{Stack: "homebrew", Command: "brew rmtree <package_name>", Description: "Uses rmtree to remove a package and its unused dependencies", Tags: []string{}},
+		{Stack: "networking", Command: "lsof -ti tcp:<port=8080> \\\n  | xargs kill -9", Description: "Kill the process on a port", Tags: []string{"process"}},
+		{Command: "echo \"\x1b[1mbold\x1b[0m\"", Description: "Untagged"},
+	}
+
+	got, err := parseWarp(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseWarp: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWarp =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestWarpRoundTrip(t *testing.T) {
+	snippets := []Snippet{
+		{Stack: "git", Command: "git push <remote=origin> <branch>", Description: "Push: a branch", Tags: []string{"vcs"}},
+		{Stack: "sh", Command: "for f in *.log; do\n  gzip \"$f\"\ndone", Description: "Compress logs", Tags: []string{}},
+	}
+
+	var b strings.Builder
+	if err := writeWarp(&b, snippets); err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseWarp(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("parseWarp: %v\n%s", err, b.String())
+	}
+	if !reflect.DeepEqual(got, snippets) {
+		t.Errorf("round trip =\n%#v\nwant\n%#v\n%s", got, snippets, b.String())
+	}
+}
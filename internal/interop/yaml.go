@@ -0,0 +1,449 @@
+package interop
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A small reader and writer for the block-style YAML used by Warp workflows
+// and cam bundles: mappings, sequences, plain and quoted scalars, flow
+// sequences of scalars and literal/folded block scalars. Scalars are decoded
+// as strings (nil for null); anchors, tags and multi-line flow or quoted
+// scalars are not supported.
+
+type yamlLine struct {
+	num    int
+	indent int
+	text   string // without the indentation
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// ParseYAML decodes every document in data into map[string]any, []any,
+// string or nil values.
+func ParseYAML(data string) ([]any, error) {
+	var docs []any
+	var lines []yamlLine
+	flush := func() error {
+		p := &yamlParser{lines: lines}
+		if _, ok := p.peek(); ok {
+			doc, err := p.parseNode(0)
+			if err != nil {
+				return err
+			}
+			if l, ok := p.peek(); ok {
+				return fmt.Errorf("line %d: unexpected indentation", l.num)
+			}
+			docs = append(docs, doc)
+		}
+		lines = nil
+		return nil
+	}
+
+	for i, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if raw == "---" || strings.HasPrefix(raw, "--- ") || raw == "..." {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if strings.HasPrefix(raw, "%") && len(lines) == 0 {
+			continue // directive
+		}
+		text := strings.TrimLeft(raw, " ")
+		lines = append(lines, yamlLine{num: i + 1, indent: len(raw) - len(text), text: text})
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// peek returns the next line with content, skipping blank and comment lines.
+func (p *yamlParser) peek() (yamlLine, bool) {
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if t := strings.TrimSpace(l.text); t != "" && !strings.HasPrefix(t, "#") {
+			return l, true
+		}
+		p.pos++
+	}
+	return yamlLine{}, false
+}
+
+func (p *yamlParser) parseNode(indent int) (any, error) {
+	l, ok := p.peek()
+	if !ok || l.indent < indent {
+		return nil, nil
+	}
+	if isSeqItem(l.text) {
+		return p.parseSeq(l.indent)
+	}
+	return p.parseMap(l.indent)
+}
+
+func (p *yamlParser) parseSeq(indent int) ([]any, error) {
+	items := []any{}
+	for {
+		l, ok := p.peek()
+		if !ok || l.indent < indent || (l.indent == indent && !isSeqItem(l.text)) {
+			return items, nil
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if rest == "" {
+			p.pos++
+			v, err := p.parseNode(indent + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+			continue
+		}
+
+		if _, _, isKey := splitYAMLKey(rest); isKey {
+			// "- key: value" starts a mapping indented past the dash.
+			p.lines[p.pos] = yamlLine{num: l.num, indent: indent + len(l.text) - len(rest), text: rest}
+			v, err := p.parseMap(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+			continue
+		}
+
+		p.pos++
+		v, err := p.value(rest, indent, l.num)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+}
+
+func (p *yamlParser) parseMap(indent int) (map[string]any, error) {
+	m := make(map[string]any)
+	for {
+		l, ok := p.peek()
+		if !ok || l.indent < indent {
+			return m, nil
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+		}
+		key, rest, isKey := splitYAMLKey(l.text)
+		if !isKey {
+			return nil, fmt.Errorf("line %d: expected 'key: value'", l.num)
+		}
+		p.pos++
+
+		if rest != "" {
+			v, err := p.value(rest, indent, l.num)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			continue
+		}
+
+		next, ok := p.peek()
+		if ok && (next.indent > indent || (next.indent == indent && isSeqItem(next.text))) {
+			v, err := p.parseNode(next.indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		} else {
+			m[key] = nil
+		}
+	}
+}
+
+// value decodes the scalar (or block scalar header) after "key:" or "- ".
+func (p *yamlParser) value(s string, indent int, num int) (any, error) {
+	switch {
+	case s[0] == '|' || s[0] == '>':
+		return p.blockScalar(strings.TrimSpace(stripYAMLComment(s)), indent), nil
+	case s[0] == '[':
+		return parseFlowSeq(stripYAMLComment(s), num)
+	case strings.HasPrefix(s, "{}"):
+		return map[string]any{}, nil
+	case s[0] == '{':
+		return nil, fmt.Errorf("line %d: flow mappings are not supported", num)
+	}
+	return parseYAMLScalar(s, num)
+}
+
+func (p *yamlParser) blockScalar(header string, parentIndent int) string {
+	var lines []string
+	blockIndent := -1
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if strings.TrimSpace(l.text) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if l.indent <= parentIndent || (blockIndent >= 0 && l.indent < blockIndent) {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = l.indent
+		}
+		lines = append(lines, strings.Repeat(" ", l.indent-blockIndent)+l.text)
+		p.pos++
+	}
+
+	// Trailing blank lines are subject to chomping.
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var text string
+	if header[0] == '>' {
+		text = foldLines(lines)
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	switch {
+	case strings.Contains(header, "-"):
+		return text
+	case strings.Contains(header, "+"):
+		return text + strings.Repeat("\n", trailing+1)
+	}
+	return text + "\n"
+}
+
+// foldLines joins folded block scalar lines: single newlines become spaces,
+// each blank line becomes a newline and more-indented lines keep their breaks.
+func foldLines(lines []string) string {
+	var b strings.Builder
+	lastText := "" // the previous non-blank line
+	for i, line := range lines {
+		switch {
+		case line == "":
+			b.WriteString("\n")
+			continue
+		case i == 0:
+		case lines[i-1] != "":
+			if strings.HasPrefix(line, " ") || strings.HasPrefix(lastText, " ") {
+				b.WriteString("\n")
+			} else {
+				b.WriteString(" ")
+			}
+		case strings.HasPrefix(line, " ") || strings.HasPrefix(lastText, " "):
+			b.WriteString("\n")
+		}
+		b.WriteString(line)
+		lastText = line
+	}
+	return b.String()
+}
+
+func parseFlowSeq(s string, num int) ([]any, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("line %d: flow sequences must end on the same line", num)
+	}
+	items := []any{}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	for inner != "" {
+		end := len(inner)
+		if inner[0] == '"' || inner[0] == '\'' {
+			closing := closingQuote(inner)
+			if closing < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", num)
+			}
+			if i := strings.IndexByte(inner[closing:], ','); i >= 0 {
+				end = closing + i
+			}
+		} else if i := strings.IndexByte(inner, ','); i >= 0 {
+			end = i
+		}
+
+		v, err := parseYAMLScalar(strings.TrimSpace(inner[:end]), num)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		if end == len(inner) {
+			break
+		}
+		inner = strings.TrimSpace(inner[end+1:])
+	}
+	return items, nil
+}
+
+func parseYAMLScalar(s string, num int) (any, error) {
+	if s == "" {
+		return nil, nil
+	}
+	switch s[0] {
+	case '"', '\'':
+		end := closingQuote(s)
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: unterminated string", num)
+		}
+		if rest := strings.TrimSpace(s[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return nil, fmt.Errorf("line %d: unexpected text after string", num)
+		}
+		if s[0] == '\'' {
+			return strings.ReplaceAll(s[1:end], "''", "'"), nil
+		}
+		v, err := unescapeYAML(s[1:end])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid string %s: %w", num, s[:end+1], err)
+		}
+		return v, nil
+	case '&', '*', '!':
+		return nil, fmt.Errorf("line %d: anchors, aliases and tags are not supported", num)
+	}
+
+	s = strings.TrimSpace(stripYAMLComment(s))
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	}
+	return s, nil
+}
+
+// yamlEscapes maps the single-character escapes of double-quoted scalars.
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v",
+	'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\",
+	'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// unescapeYAML decodes the escapes of a double-quoted scalar, which are a
+// superset of Go's (\e, \/ and \N, for example).
+func unescapeYAML(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", fmt.Errorf("trailing backslash")
+		}
+		if esc, ok := yamlEscapes[s[i]]; ok {
+			b.WriteString(esc)
+			continue
+		}
+		size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[i]]
+		if size == 0 {
+			return "", fmt.Errorf("invalid escape \\%c", s[i])
+		}
+		if i+size >= len(s) {
+			return "", fmt.Errorf("short escape \\%s", s[i:])
+		}
+		code, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return "", fmt.Errorf("invalid escape \\%s", s[i:i+1+size])
+		}
+		b.WriteRune(rune(code))
+		i += size
+	}
+	return b.String(), nil
+}
+
+// closingQuote returns the index of the quote closing the string s starts with.
+func closingQuote(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case q == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+func stripYAMLComment(s string) string {
+	if i := strings.Index(s, " #"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits "key: value", honouring quoted keys.
+func splitYAMLKey(text string) (string, string, bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 || !strings.HasPrefix(text[end+1:], ":") {
+			return "", "", false
+		}
+		key, err := parseYAMLScalar(text[:end+1], 0)
+		if err != nil {
+			return "", "", false
+		}
+		rest := text[end+2:]
+		if rest != "" && rest[0] != ' ' {
+			return "", "", false
+		}
+		return key.(string), strings.TrimSpace(rest), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i == len(text)-1 || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+		if text[i] == ' ' && i+1 < len(text) && text[i+1] == '#' {
+			break
+		}
+	}
+	return "", "", false
+}
+
+// YAMLString formats s as a scalar, quoting it when a plain scalar would be
+// read back differently.
+func YAMLString(s string) string {
+	if s == "" || strings.ContainsAny(s, "\n\t\r\"\\") || strings.TrimSpace(s) != s ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'%@`") || strings.Contains(s, ": ") ||
+		strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// YAMLBlock formats s as the value of a key: a literal block scalar indented
+// by indent when it spans several lines, a scalar otherwise.
+func YAMLBlock(s string, indent string) string {
+	if !strings.Contains(s, "\n") || strings.HasPrefix(s, " ") || strings.HasSuffix(s, "\n") ||
+		strings.ContainsAny(s, "\t\r") {
+		return YAMLString(s)
+	}
+	return "|-\n" + indent + strings.ReplaceAll(s, "\n", "\n"+indent)
+}
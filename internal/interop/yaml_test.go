@@ -0,0 +1,141 @@
+package interop
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []any
+	}{
+		{
+			name: "mapping with nested sequence",
+			input: `name: Remove a package # comment
+tags: ["homebrew", 'it''s', plain]
+arguments:
+  - name: package_name
+    default_value: ~
+  - name: "quoted: key"
+empty: {}
+`,
+			want: []any{map[string]any{
+				"name": "Remove a package",
+				"tags": []any{"homebrew", "it's", "plain"},
+				"arguments": []any{
+					map[string]any{"name": "package_name", "default_value": nil},
+					map[string]any{"name": "quoted: key"},
+				},
+				"empty": map[string]any{},
+			}},
+		},
+		{
+			name: "escapes in double-quoted strings",
+			input: `a: "tab\there \"quoted\" back\\slash"
+b: "é \x41 \e[1m \/ \u00e9"
+c: 'single \n stays'
+`,
+			want: []any{map[string]any{
+				"a": "tab\there \"quoted\" back\\slash",
+				"b": "é A \x1b[1m / é",
+				"c": `single \n stays`,
+			}},
+		},
+		{
+			name: "block scalars",
+			input: `literal: |
+  for f in *; do
+    echo "$f"
+  done
+strip: |-
+  one
+  two
+
+keep: |+
+  kept
+
+folded: >
+  joined
+  into one
+
+  paragraph
+indented: >
+  a
+    b
+  c
+after: x
+`,
+			want: []any{map[string]any{
+				"literal":  "for f in *; do\n  echo \"$f\"\ndone\n",
+				"strip":    "one\ntwo",
+				"keep":     "kept\n\n",
+				"folded":   "joined into one\nparagraph\n",
+				"indented": "a\n  b\nc\n",
+				"after":    "x",
+			}},
+		},
+		{
+			name:  "multiple documents",
+			input: "---\na: 1\n---\n- x\n- y\n...\n",
+			want:  []any{map[string]any{"a": "1"}, []any{"x", "y"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseYAML(tt.input)
+			if err != nil {
+				t.Fatalf("ParseYAML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseYAML =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	for _, input := range []string{
+		"a: \"unterminated\n",
+		"a: &anchor x\n",
+		"a: {b: c}\n",
+		"a: \"bad \\q escape\"\n",
+		"a: [x, y\n",
+		"a: x\n  b: y\n",
+	} {
+		if _, err := ParseYAML(input); err == nil {
+			t.Errorf("ParseYAML(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestYAMLStringRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"plain", "", " padded ", "- dash", "key: value", "trailing:", "a #comment",
+		"true", "no", "~", "1.5", "tab\there", `back\slash`, `"quoted"`, "it's", "\x1b[1m",
+	} {
+		docs, err := ParseYAML("v: " + YAMLString(s) + "\n")
+		if err != nil {
+			t.Errorf("YAMLString(%q) = %s: %v", s, YAMLString(s), err)
+			continue
+		}
+		if got := docs[0].(map[string]any)["v"]; got != s {
+			t.Errorf("YAMLString(%q) = %s, read back as %#v", s, YAMLString(s), got)
+		}
+	}
+}
+
+func TestYAMLBlockRoundTrip(t *testing.T) {
+	for _, s := range []string{"single", "two\nlines", "  indented\nfirst", "ends\n", "  nested\n    deeper"} {
+		docs, err := ParseYAML("v: " + YAMLBlock(s, "  ") + "\n")
+		if err != nil {
+			t.Errorf("YAMLBlock(%q): %v", s, err)
+			continue
+		}
+		if got := docs[0].(map[string]any)["v"]; got != s {
+			t.Errorf("YAMLBlock(%q) read back as %#v", s, got)
+		}
+	}
+}
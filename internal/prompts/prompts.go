@@ -0,0 +1,156 @@
+package prompts
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"cam/internal/data"
+	"cam/internal/sysinfo"
+)
+
+//go:embed defaults/*.tmpl
+var defaults embed.FS
+
+// Example is a saved command offered to the model as a reference.
+type Example struct {
+	Command     string
+	Description string
+}
+
+// Failure describes a failed command for the fix prompt.
+type Failure struct {
+	Command  string
+	ExitCode int // 0 or negative when unknown
+	Stderr   string
+}
+
+// Vars are the variables available to every prompt template.
+type Vars struct {
+	Question    string
+	Context     string
+	Shell       string
+	OS          string
+	Distro      string
+	Coreutils   string   // "GNU", "BSD" or "BusyBox"
+	Tools       []string // notable tools installed on PATH
+	Unavailable []string // executables a previous answer used that aren't installed
+	Mode        string   // "oneline" or "markdown" for ask
+	Format      string   // "json" when the answer is constrained to a JSON schema (cmdr, fix)
+	Count       int      // number of alternatives requested
+	Examples    []Example
+	Failure     Failure
+	Stack       string   // stack the command belongs to (enrich)
+	Tags        []string // tags already used in the user's stacks (enrich)
+}
+
+// NewVars returns Vars for question and context with the environment filled in.
+func NewVars(question, context string) Vars {
+	info := sysinfo.Detect()
+	return Vars{
+		Question:  question,
+		Context:   context,
+		Shell:     info.Shell,
+		OS:        info.OS,
+		Distro:    info.Distro,
+		Coreutils: info.Coreutils,
+		Tools:     info.Tools,
+		Mode:      "markdown",
+	}
+}
+
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Dir returns the directory holding user overrides, prompts/ in the profile's config directory.
+func Dir() (string, error) {
+	dir, err := data.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "prompts"), nil
+}
+
+// Names lists the built-in prompt templates.
+func Names() []string {
+	entries, _ := defaults.ReadDir("defaults")
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".tmpl"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the built-in source of the named template.
+func Default(name string) (string, error) {
+	content, err := defaults.ReadFile("defaults/" + name + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("unknown prompt '%s' (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return string(content), nil
+}
+
+// OverridePath returns where a user override for name lives, whether or not it exists.
+func OverridePath(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".tmpl"), nil
+}
+
+// Source returns the effective template source for name and whether it is a user override.
+func Source(name string) (string, bool, error) {
+	def, err := Default(name)
+	if err != nil {
+		return "", false, err
+	}
+
+	path, err := OverridePath(name)
+	if err != nil {
+		return def, false, nil
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return def, false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read prompt override: %w", err)
+	}
+	return string(content), true, nil
+}
+
+// Validate parses src as a template, returning any syntax error.
+func Validate(name, src string) error {
+	_, err := template.New(name).Funcs(funcs).Parse(src)
+	return err
+}
+
+// Render executes the effective template for name with vars.
+func Render(name string, vars Vars) (string, error) {
+	src, custom, err := Source(name)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Parse(src)
+	if err != nil {
+		if custom {
+			return "", fmt.Errorf("invalid prompt override for '%s' (fix it with 'cam prompts edit %s' or 'cam prompts reset %s'): %w", name, name, name, err)
+		}
+		return "", fmt.Errorf("invalid built-in prompt '%s': %w", name, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, vars); err != nil {
+		return "", fmt.Errorf("failed to render prompt '%s': %w", name, err)
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
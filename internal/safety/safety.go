@@ -0,0 +1,417 @@
+package safety
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Risk describes why a command was classified as destructive.
+type Risk struct {
+	Reason string
+}
+
+// wrappers are commands that execute their arguments as another command,
+// with the options of each that take a separate value (e.g. sudo -u root).
+var wrappers = map[string]wrapperOptions{
+	"sudo":    {short: "CDghprTtUu", long: []string{"--chdir", "--close-from", "--group", "--host", "--other-user", "--prompt", "--role", "--type", "--user"}},
+	"doas":    {short: "Cu"},
+	"env":     {short: "Cu", long: []string{"--chdir", "--unset"}},
+	"nohup":   {},
+	"time":    {short: "fo", long: []string{"--format", "--output"}},
+	"exec":    {short: "a"},
+	"xargs":   {short: "adEILnPs", long: []string{"--arg-file", "--delimiter", "--max-args", "--max-chars", "--max-lines", "--max-procs", "--replace"}},
+	"command": {},
+}
+
+type wrapperOptions struct {
+	short string   // single-letter options followed by a value
+	long  []string // long options followed by a separate value
+}
+
+// unwrap drops a wrapper's options and environment assignments from args
+// (which follow the wrapper's name), returning the wrapped command.
+func unwrap(wrapper string, args []string) []string {
+	opts := wrappers[wrapper]
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--":
+			args = args[1:]
+			return skipAssignments(args)
+		case strings.HasPrefix(arg, "--"):
+			args = args[1:]
+			if !strings.Contains(arg, "=") && slices.Contains(opts.long, arg) && len(args) > 0 {
+				args = args[1:]
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			args = args[1:]
+			// In a cluster like -Eu, a value option takes the rest of the
+			// cluster or, when it is last, the next argument.
+			for i, c := range arg[1:] {
+				if strings.ContainsRune(opts.short, c) {
+					if i == len(arg)-2 && len(args) > 0 {
+						args = args[1:]
+					}
+					break
+				}
+			}
+		default:
+			return skipAssignments(args)
+		}
+	}
+	return args
+}
+
+// skipAssignments drops leading NAME=value arguments, as in env FOO=1 cmd.
+func skipAssignments(args []string) []string {
+	for len(args) > 0 && strings.Contains(args[0], "=") && !strings.HasPrefix(args[0], "=") {
+		args = args[1:]
+	}
+	return args
+}
+
+// gitSubcommand skips git's global options (git -C dir -c k=v push ...) and
+// returns the subcommand and its arguments.
+func gitSubcommand(params []string) (string, []string) {
+	for len(params) > 0 {
+		p := params[0]
+		switch {
+		case p == "-C" || p == "-c" || p == "--git-dir" || p == "--work-tree" || p == "--namespace" || p == "--exec-path":
+			if len(params) < 2 {
+				return "", nil
+			}
+			params = params[2:]
+		case strings.HasPrefix(p, "-"):
+			params = params[1:]
+		default:
+			return p, params[1:]
+		}
+	}
+	return "", nil
+}
+
+var shells = map[string]bool{
+	"sh":   true,
+	"bash": true,
+	"zsh":  true,
+	"dash": true,
+	"ksh":  true,
+	"fish": true,
+}
+
+// Analyze parses command with a POSIX/bash shell parser and returns every
+// destructive pattern it finds. An empty result means nothing risky was detected.
+func Analyze(command string) []Risk {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return []Risk{{Reason: fmt.Sprintf("command could not be parsed (%v)", err)}}
+	}
+
+	var risks []Risk
+	seen := make(map[string]bool)
+	add := func(reason string) {
+		if !seen[reason] {
+			seen[reason] = true
+			risks = append(risks, Risk{Reason: reason})
+		}
+	}
+
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch n := node.(type) {
+		case *syntax.CallExpr:
+			for _, reason := range analyzeCall(n) {
+				add(reason)
+			}
+		case *syntax.BinaryCmd:
+			if (n.Op == syntax.Pipe || n.Op == syntax.PipeAll) && isDownload(n.X) && isShell(n.Y) {
+				add("pipes a downloaded script straight into a shell")
+			}
+		case *syntax.Redirect:
+			if reason := analyzeRedirect(n); reason != "" {
+				add(reason)
+			}
+		}
+		return true
+	})
+
+	return risks
+}
+
+// analyzeCall checks a single simple command against the known destructive patterns.
+func analyzeCall(call *syntax.CallExpr) []string {
+	args := callArgs(call)
+	if len(args) == 0 {
+		return nil
+	}
+
+	name := filepath.Base(args[0])
+	params := args[1:]
+
+	var reasons []string
+	switch {
+	case name == "rm":
+		if hasFlag(params, 'r', "--recursive") || hasFlag(params, 'R', "--recursive") {
+			if hasFlag(params, 'f', "--force") {
+				reasons = append(reasons, fmt.Sprintf("rm -rf force-deletes %s recursively", describeTargets(params)))
+			}
+		}
+
+	case name == "dd":
+		target := "its output"
+		for _, p := range params {
+			if strings.HasPrefix(p, "of=") {
+				target = strings.TrimPrefix(p, "of=")
+			}
+		}
+		reasons = append(reasons, fmt.Sprintf("dd writes raw data to %s", target))
+
+	case name == "mkfs" || strings.HasPrefix(name, "mkfs."):
+		reasons = append(reasons, "mkfs formats a filesystem, erasing its contents")
+
+	case name == "git":
+		sub, subParams := gitSubcommand(params)
+		if sub != "push" {
+			break
+		}
+		for _, p := range subParams {
+			if p == "-f" || p == "--force" || strings.HasPrefix(p, "--force-with-lease") || strings.HasPrefix(p, "+") {
+				reasons = append(reasons, "force push rewrites remote history")
+				break
+			}
+		}
+
+	case name == "chmod":
+		if hasFlag(params, 'R', "--recursive") {
+			for _, p := range params {
+				if p == "777" || p == "0777" || p == "a+rwx" {
+					reasons = append(reasons, "chmod -R 777 makes files world-writable recursively")
+					break
+				}
+			}
+		}
+
+	case shells[name]:
+		// Look inside `sh -c "..."` so wrapping doesn't hide anything.
+		for i, p := range params {
+			if p == "-c" && i+1 < len(params) {
+				for _, r := range Analyze(params[i+1]) {
+					reasons = append(reasons, r.Reason)
+				}
+				break
+			}
+		}
+	}
+
+	return reasons
+}
+
+// analyzeRedirect flags output redirections that would truncate an existing file.
+func analyzeRedirect(r *syntax.Redirect) string {
+	switch r.Op {
+	case syntax.RdrOut, syntax.ClbOut, syntax.RdrAll:
+	default:
+		return ""
+	}
+
+	target, ok := wordLiteral(r.Word)
+	if !ok || strings.HasPrefix(target, "/dev/") {
+		return ""
+	}
+
+	info, err := os.Stat(expandHome(target))
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+
+	return fmt.Sprintf("redirection overwrites existing file %s", target)
+}
+
+// callArgs returns the literal arguments of call with wrappers such as sudo stripped.
+func callArgs(call *syntax.CallExpr) []string {
+	var args []string
+	for _, w := range call.Args {
+		lit, _ := wordLiteral(w)
+		args = append(args, lit)
+	}
+
+	for len(args) > 0 {
+		wrapper := filepath.Base(args[0])
+		if _, ok := wrappers[wrapper]; !ok {
+			break
+		}
+		// Skip wrapper options and env assignments (e.g. sudo -u root, env FOO=1).
+		args = unwrap(wrapper, args[1:])
+	}
+
+	return args
+}
+
+// wordLiteral resolves a word made only of literal and quoted parts.
+func wordLiteral(w *syntax.Word) (string, bool) {
+	if w == nil {
+		return "", false
+	}
+
+	var sb strings.Builder
+	for _, part := range w.Parts {
+		switch p := part.(type) {
+		case *syntax.Lit:
+			sb.WriteString(p.Value)
+		case *syntax.SglQuoted:
+			sb.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			for _, inner := range p.Parts {
+				lit, ok := inner.(*syntax.Lit)
+				if !ok {
+					return "", false
+				}
+				sb.WriteString(lit.Value)
+			}
+		default:
+			return "", false
+		}
+	}
+	return sb.String(), true
+}
+
+// hasFlag reports whether params contain the short flag (alone or combined) or the long form.
+func hasFlag(params []string, short rune, long string) bool {
+	for _, p := range params {
+		if p == "--" {
+			return false
+		}
+		if p == long {
+			return true
+		}
+		if strings.HasPrefix(p, "-") && !strings.HasPrefix(p, "--") && strings.ContainsRune(p[1:], short) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeTargets(params []string) string {
+	var targets []string
+	for _, p := range params {
+		if !strings.HasPrefix(p, "-") {
+			if p == "" {
+				p = "<dynamic path>"
+			}
+			targets = append(targets, p)
+		}
+	}
+	if len(targets) == 0 {
+		return "its targets"
+	}
+	return strings.Join(targets, " ")
+}
+
+// isDownload reports whether stmt contains a curl or wget invocation.
+func isDownload(stmt *syntax.Stmt) bool {
+	found := false
+	syntax.Walk(stmt, func(node syntax.Node) bool {
+		if call, ok := node.(*syntax.CallExpr); ok {
+			if args := callArgs(call); len(args) > 0 {
+				switch filepath.Base(args[0]) {
+				case "curl", "wget":
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// isShell reports whether stmt starts by invoking a shell interpreter.
+func isShell(stmt *syntax.Stmt) bool {
+	found := false
+	syntax.Walk(stmt, func(node syntax.Node) bool {
+		if call, ok := node.(*syntax.CallExpr); ok {
+			if args := callArgs(call); len(args) > 0 && shells[filepath.Base(args[0])] {
+				found = true
+			}
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// builtins are shell builtins and keywords that never need to be on PATH.
+var builtins = map[string]bool{
+	".": true, ":": true, "[": true, "[[": true, "alias": true, "bg": true, "break": true,
+	"builtin": true, "cd": true, "command": true, "continue": true, "declare": true, "echo": true, "eval": true,
+	"exec": true, "exit": true, "export": true, "false": true, "fg": true, "getopts": true, "hash": true,
+	"jobs": true, "let": true, "local": true, "printf": true, "pwd": true, "read": true,
+	"readonly": true, "return": true, "set": true, "shift": true, "source": true, "test": true,
+	"time": true, "trap": true, "true": true, "type": true, "typeset": true, "ulimit": true, "umask": true,
+	"unalias": true, "unset": true, "wait": true,
+}
+
+// IsBuiltin reports whether name is a shell builtin or keyword.
+func IsBuiltin(name string) bool {
+	return builtins[name]
+}
+
+// Executables returns the external programs command invokes, in order of first
+// use, skipping shell builtins, functions defined in the command and names that
+// are only known at runtime.
+func Executables(command string) ([]string, error) {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return nil, err
+	}
+
+	functions := make(map[string]bool)
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if fn, ok := node.(*syntax.FuncDecl); ok {
+			functions[fn.Name.Value] = true
+		}
+		return true
+	})
+
+	var names []string
+	seen := make(map[string]bool)
+	syntax.Walk(file, func(node syntax.Node) bool {
+		call, ok := node.(*syntax.CallExpr)
+		if !ok {
+			return true
+		}
+
+		var args []string
+		for _, w := range call.Args {
+			lit, _ := wordLiteral(w)
+			args = append(args, lit)
+		}
+		// Wrappers like sudo are programs too; record them and what they run.
+		for len(args) > 0 {
+			name := args[0]
+			if name != "" && !builtins[name] && !functions[name] && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+			if _, ok := wrappers[filepath.Base(name)]; !ok {
+				break
+			}
+			args = unwrap(filepath.Base(name), args[1:])
+		}
+		return true
+	})
+
+	return names, nil
+}
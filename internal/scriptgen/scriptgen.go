@@ -0,0 +1,350 @@
+// Package scriptgen turns stacks into shell aliases and functions, runnable
+// scripts and Make or just targets, for places where cam isn't installed
+// (CI images, colleagues' machines).
+//
+// Placeholders (<name> or <name=default>) become positional arguments in
+// functions, environment variables in scripts, make variables in Makefiles
+// and recipe parameters in justfiles.
+package scriptgen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cam/internal/interop"
+)
+
+const (
+	Aliases   = "aliases"
+	Functions = "functions"
+	Script    = "script"
+	Makefile  = "makefile"
+	Justfile  = "justfile"
+)
+
+// Kinds lists the supported outputs.
+var Kinds = []string{Aliases, Functions, Script, Makefile, Justfile}
+
+// Generate writes snippets as kind. With prefix, every name starts with its
+// stack's name (git_status rather than status).
+func Generate(w io.Writer, kind string, snippets []interop.Snippet, prefix bool) error {
+	bw := bufio.NewWriter(w)
+	switch kind {
+	case Aliases:
+		writeShell(bw, snippets, prefix, true)
+	case Functions:
+		writeShell(bw, snippets, prefix, false)
+	case Script:
+		writeScript(bw, snippets)
+	case Makefile:
+		writeMakefile(bw, snippets, prefix)
+	case Justfile:
+		writeJustfile(bw, snippets, prefix)
+	default:
+		return fmt.Errorf("unknown output '%s' (supported: %s)", kind, strings.Join(Kinds, ", "))
+	}
+	return bw.Flush()
+}
+
+// writeShell writes aliases, or functions for commands an alias can't hold
+// (parameters, several lines) and for everything when aliases is false.
+func writeShell(w *bufio.Writer, snippets []interop.Snippet, prefix bool, aliases bool) {
+	fmt.Fprintf(w, "# Generated by cam. Source this file from your shell profile.\n")
+	names := Names(snippets, "_", prefix)
+	for i, s := range snippets {
+		w.WriteString("\n")
+		if s.Description != "" {
+			fmt.Fprintf(w, "# %s\n", oneLine(s.Description))
+		}
+
+		params := interop.Params(s.Command)
+		if aliases && len(params) == 0 && !strings.Contains(s.Command, "\n") {
+			fmt.Fprintf(w, "alias %s=%s\n", names[i], shellQuote(s.Command))
+			continue
+		}
+
+		body := interop.ReplaceParams(s.Command, func(p interop.Param) string {
+			n := paramIndex(params, p.Name) + 1
+			if p.Default != "" {
+				return fmt.Sprintf("${%d:-%s}", n, shellDefault(p.Default))
+			}
+			return fmt.Sprintf("${%d:?%s is required}", n, p.Name)
+		})
+		if len(params) == 0 && simpleCommand(body) {
+			body += ` "$@"`
+		}
+		body = callCommand(body, names[i])
+		fmt.Fprintf(w, "%s() {\n%s\n}\n", names[i], indent(body, "\t"))
+	}
+}
+
+// writeScript writes a bash script that runs the commands in stack order.
+// Parameters are read from environment variables.
+func writeScript(w *bufio.Writer, snippets []interop.Snippet) {
+	w.WriteString("#!/usr/bin/env bash\n")
+	var stacks []string
+	for _, group := range interop.GroupByStack(snippets) {
+		stacks = append(stacks, group[0].Stack)
+	}
+	fmt.Fprintf(w, "# Generated by cam from %s.\n", strings.Join(stacks, ", "))
+
+	var params []interop.Param
+	for _, s := range snippets {
+		for _, p := range interop.Params(s.Command) {
+			if paramIndex(params, p.Name) < 0 {
+				params = append(params, p)
+			}
+		}
+	}
+	if len(params) > 0 {
+		w.WriteString("#\n# Set these environment variables:\n")
+		for _, p := range params {
+			if p.Default != "" {
+				fmt.Fprintf(w, "#   %s (default: %s)\n", varName(p.Name), p.Default)
+			} else {
+				fmt.Fprintf(w, "#   %s (required)\n", varName(p.Name))
+			}
+		}
+	}
+	w.WriteString("set -euo pipefail\n")
+
+	for _, s := range snippets {
+		label := oneLine(s.Description)
+		if label == "" {
+			label = oneLine(s.Command)
+		}
+		body := interop.ReplaceParams(s.Command, func(p interop.Param) string {
+			if p.Default != "" {
+				return fmt.Sprintf("${%s:-%s}", varName(p.Name), shellDefault(p.Default))
+			}
+			return fmt.Sprintf("${%s:?set %s}", varName(p.Name), varName(p.Name))
+		})
+		fmt.Fprintf(w, "\necho %s\n%s\n", shellQuote("==> "+label), body)
+	}
+}
+
+// writeMakefile writes one phony target per command. Parameters become make
+// variables, given on the command line (make target NAME=value).
+func writeMakefile(w *bufio.Writer, snippets []interop.Snippet, prefix bool) {
+	w.WriteString("# Generated by cam.\n")
+	names := Names(snippets, "-", prefix)
+
+	var defaults []interop.Param
+	for _, s := range snippets {
+		for _, p := range interop.Params(s.Command) {
+			if p.Default != "" && paramIndex(defaults, p.Name) < 0 {
+				defaults = append(defaults, p)
+			}
+		}
+	}
+	if len(defaults) > 0 {
+		w.WriteString("\n")
+		for _, p := range defaults {
+			fmt.Fprintf(w, "%s ?= %s\n", varName(p.Name), makeValue.Replace(p.Default))
+		}
+	}
+	fmt.Fprintf(w, "\n.PHONY: %s\n", strings.Join(names, " "))
+
+	for i, s := range snippets {
+		w.WriteString("\n")
+		if s.Description != "" {
+			fmt.Fprintf(w, "## %s\n", oneLine(s.Description))
+		}
+		body := interop.ReplaceParams(strings.ReplaceAll(s.Command, "$", "$$"), func(p interop.Param) string {
+			if p.Default != "" {
+				return fmt.Sprintf("$(%s)", varName(p.Name))
+			}
+			return fmt.Sprintf("$(or $(%s),$(error %s is not set))", varName(p.Name), varName(p.Name))
+		})
+		fmt.Fprintf(w, "%s:\n%s\n", names[i], indent(recipe(body), "\t"))
+	}
+}
+
+// writeJustfile writes one recipe per command, with parameters as recipe
+// parameters (just target value), required ones first.
+func writeJustfile(w *bufio.Writer, snippets []interop.Snippet, prefix bool) {
+	w.WriteString("# Generated by cam.\n")
+	names := Names(snippets, "-", prefix)
+	for i, s := range snippets {
+		w.WriteString("\n")
+		if s.Description != "" {
+			fmt.Fprintf(w, "# %s\n", oneLine(s.Description))
+		}
+
+		// just only allows parameters with defaults at the end.
+		header, optional := names[i], ""
+		for _, p := range interop.Params(s.Command) {
+			if p.Default == "" {
+				header += " " + identifier(p.Name)
+			} else {
+				optional += " " + identifier(p.Name) + "=" + justString(p.Default)
+			}
+		}
+		header += optional
+		body := interop.ReplaceParams(strings.ReplaceAll(s.Command, "{{", "{{{{"), func(p interop.Param) string {
+			return "{{" + identifier(p.Name) + "}}"
+		})
+		fmt.Fprintf(w, "%s:\n%s\n", header, indent(recipe(body), "    "))
+	}
+}
+
+// Names derives a unique name for each snippet from its description, or
+// from its command when it has none, joining words with sep.
+func Names(snippets []interop.Snippet, sep string, prefix bool) []string {
+	names := make([]string, len(snippets))
+	seen := make(map[string]int)
+	for i, s := range snippets {
+		base := slug(s.Description, sep, 4)
+		if base == "" {
+			base = slug(interop.ReplaceParams(strings.SplitN(s.Command, "\n", 2)[0], func(interop.Param) string { return "" }), sep, 3)
+		}
+		if prefix {
+			stack := slug(s.Stack, sep, 3)
+			if base != stack && !strings.HasPrefix(base, stack+sep) && stack != "" {
+				base = stack + sep + base
+			}
+		}
+		if base == "" || unicode.IsDigit(rune(base[0])) {
+			base = "cmd" + sep + base
+			base = strings.TrimSuffix(base, sep)
+		}
+
+		seen[base]++
+		name := base
+		if seen[base] > 1 {
+			name = base + sep + strconv.Itoa(seen[base])
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// slug keeps the first maxWords lowercase alphanumeric words of s.
+func slug(s string, sep string, maxWords int) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	if len(words) > maxWords {
+		words = words[:maxWords]
+	}
+	return strings.Join(words, sep)
+}
+
+// identifier turns a placeholder name into a shell/just identifier.
+func identifier(name string) string {
+	id := strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+	if unicode.IsDigit(rune(id[0])) {
+		id = "_" + id
+	}
+	return id
+}
+
+// varName is the environment or make variable for a placeholder.
+func varName(name string) string {
+	return strings.ToUpper(identifier(name))
+}
+
+func paramIndex(params []interop.Param, name string) int {
+	for i, p := range params {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// recipe joins the lines of a multi-line command so Make and just, which
+// run every recipe line in its own shell, run it as one. Lines that open a
+// block (do, then, {) or already end in an operator get no extra ";".
+func recipe(command string) string {
+	var lines []string
+	for _, line := range strings.Split(command, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	for i := 0; i < len(lines)-1; i++ {
+		trimmed := strings.TrimRight(lines[i], " \t")
+		fields := strings.Fields(trimmed)
+		switch {
+		case strings.HasSuffix(trimmed, `\`):
+		case strings.HasSuffix(trimmed, "&"), strings.HasSuffix(trimmed, "|"), strings.HasSuffix(trimmed, ";"),
+			opensBlock[fields[len(fields)-1]]:
+			lines[i] = trimmed + ` \`
+		default:
+			lines[i] = trimmed + `; \`
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// opensBlock holds the words after which a shell command continues without a separator.
+var opensBlock = map[string]bool{"do": true, "then": true, "else": true, "in": true, "{": true, "(": true}
+
+// callCommand makes lines of a function body that start with the function's
+// own name run the command instead, so "docker() { docker ...; }" doesn't
+// call itself forever.
+func callCommand(body string, name string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if fields := strings.Fields(trimmed); len(fields) > 0 && fields[0] == name {
+			lines[i] = line[:len(line)-len(trimmed)] + "command " + trimmed
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// simpleCommand reports whether extra arguments can be appended to command.
+func simpleCommand(command string) bool {
+	return !strings.ContainsAny(command, "\n;&|<>")
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellDefault writes a placeholder default for a ${name:-default}
+// expansion. Anything beyond plain words is double-quoted, which reads the
+// same whether or not the command puts the expansion in double quotes.
+func shellDefault(s string) string {
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,~", r))
+	}) < 0 {
+		return s
+	}
+	return `"` + shellEscape.Replace(s) + `"`
+}
+
+// shellEscape escapes the characters that stay special inside double quotes.
+var shellEscape = strings.NewReplacer(`\`, `\\`, `$`, `\$`, "`", "\\`", `"`, `\"`)
+
+// makeValue escapes a make variable value: "$" would start a reference and
+// "#" a comment.
+var makeValue = strings.NewReplacer("$", "$$", "#", `\#`)
+
+// justString quotes s as a just string literal.
+func justString(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	return strconv.Quote(s)
+}
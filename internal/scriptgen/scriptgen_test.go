@@ -0,0 +1,90 @@
+package scriptgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cam/internal/interop"
+)
+
+func TestRecipe(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"make build", "make build"},
+		{"cd src\nmake", "cd src; \\\nmake"},
+		{"for f in *; do\n  echo $f\ndone", "for f in *; do \\\n  echo $f; \\\ndone"},
+		{"if true; then\n  a\nelse\n  b\nfi", "if true; then \\\n  a; \\\nelse \\\n  b; \\\nfi"},
+		{"{\n  a\n} &&\nb |\nc", "{ \\\n  a; \\\n} && \\\nb | \\\nc"},
+		{"a &\n\nwait", "a & \\\nwait"},
+		{"docker run \\\n  --rm x", "docker run \\\n  --rm x"},
+	}
+
+	for _, tt := range tests {
+		if got := recipe(tt.command); got != tt.want {
+			t.Errorf("recipe(%q) =\n%s\nwant\n%s", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestCallCommand(t *testing.T) {
+	tests := []struct {
+		body, name, want string
+	}{
+		{`docker ps "$@"`, "docker", `command docker ps "$@"`},
+		{"cd /tmp\n  ls -la", "ls", "cd /tmp\n  command ls -la"},
+		{"dockerd --debug", "docker", "dockerd --debug"},
+	}
+
+	for _, tt := range tests {
+		if got := callCommand(tt.body, tt.name); got != tt.want {
+			t.Errorf("callCommand(%q, %q) = %q, want %q", tt.body, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	snippets := []interop.Snippet{
+		{Stack: "git", Command: "git status", Description: "Show status"},
+		{Stack: "git", Command: `git log -n <count=10> --format="<format=%h %s>"`, Description: "Recent log"},
+		{Stack: "deploy", Command: "cd <dir=/tmp>\nmake <target>"},
+		{Stack: "misc", Command: `printf '[%s]\n' <word=a}b> "<text=it's "$HOME" ` + "`id`" + ` \ #1>"`, Description: "Quote defaults"},
+	}
+
+	for _, kind := range Kinds {
+		t.Run(kind, func(t *testing.T) {
+			want, err := os.ReadFile(filepath.Join("testdata", kind+".golden"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := Generate(&b, kind, snippets, true); err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if got := b.String(); got != string(want) {
+				t.Errorf("Generate(%s) =\n%s\nwant\n%s", kind, got, want)
+			}
+		})
+	}
+}
+
+func TestShellDefault(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"main", "main"},
+		{"~/src", "~/src"},
+		{"a b", `"a b"`},
+		{"a}b", `"a}b"`},
+		{`$HOME "x" ` + "`id`" + ` \`, `"\$HOME \"x\" \` + "`id\\`" + ` \\"`},
+	}
+
+	for _, tt := range tests {
+		if got := shellDefault(tt.value); got != tt.want {
+			t.Errorf("shellDefault(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"cam/internal/data"
+)
+
+const cacheFile = "embeddings.json"
+
+// embeddingCache holds vectors per model, keyed by the hash of the embedded text.
+type embeddingCache struct {
+	path    string
+	models  map[string]map[string][]float64
+	Vectors map[string][]float64 // vectors for the active model
+}
+
+func hashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCache reads the cache for model. A missing or unreadable cache starts empty.
+func loadCache(model string) *embeddingCache {
+	c := &embeddingCache{models: make(map[string]map[string][]float64)}
+
+	if dir, err := data.DataDir(); err == nil {
+		c.path = filepath.Join(dir, cacheFile)
+		if content, err := os.ReadFile(c.path); err == nil {
+			_ = json.Unmarshal(content, &c.models)
+		}
+	}
+
+	if c.models[model] == nil {
+		c.models[model] = make(map[string][]float64)
+	}
+	c.Vectors = c.models[model]
+	return c
+}
+
+// prune drops vectors for commands that no longer exist.
+func (c *embeddingCache) prune(keep []string) {
+	wanted := make(map[string]bool, len(keep))
+	for _, k := range keep {
+		wanted[k] = true
+	}
+	for k := range c.Vectors {
+		if !wanted[k] {
+			delete(c.Vectors, k)
+		}
+	}
+}
+
+// save writes the cache back to disk. Failures only cost a re-embed next time.
+func (c *embeddingCache) save() {
+	if c.path == "" {
+		return
+	}
+
+	content, err := json.Marshal(c.models)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return
+	}
+	if err := os.WriteFile(c.path, content, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write embedding cache: %v\n", err)
+	}
+}
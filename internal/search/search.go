@@ -0,0 +1,132 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"cam/internal/ai"
+	"cam/internal/data"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Item is a stored command together with its location.
+type Item struct {
+	Stack   string
+	Index   int
+	Command data.Command
+}
+
+// Result is a ranked search hit. Score is cosine similarity for semantic
+// search and the fuzzy match score otherwise.
+type Result struct {
+	Item
+	Score float64
+}
+
+// Items flattens stacks into a list of searchable items ordered by stack name and index.
+func Items(stacks map[string][]data.Command) []Item {
+	var names []string
+	for name := range stacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var items []Item
+	for _, name := range names {
+		for i, c := range stacks[name] {
+			items = append(items, Item{Stack: name, Index: i, Command: c})
+		}
+	}
+	return items
+}
+
+// Text is what gets embedded for an item: the command plus its description and tags.
+func (it Item) Text() string {
+	parts := []string{it.Command.Cmd}
+	if it.Command.Description != "" {
+		parts = append(parts, it.Command.Description)
+	}
+	if len(it.Command.Tags) > 0 {
+		parts = append(parts, "tags: "+strings.Join(it.Command.Tags, ", "))
+	}
+	return strings.Join(parts, "\n")
+}
+
+type commandSource []Item
+
+func (s commandSource) String(i int) string {
+	return s[i].Command.Cmd
+}
+
+func (s commandSource) Len() int {
+	return len(s)
+}
+
+// Fuzzy ranks items by character-level fuzzy matching of query against the command text.
+func Fuzzy(items []Item, query string) []Result {
+	matches := fuzzy.FindFrom(query, commandSource(items))
+
+	results := make([]Result, len(matches))
+	for i, match := range matches {
+		results[i] = Result{Item: items[match.Index], Score: float64(match.Score)}
+	}
+	return results
+}
+
+// Semantic ranks items by cosine similarity between their embeddings and the
+// embedding of query. Item vectors are cached on disk by content hash.
+func Semantic(ctx context.Context, configStore *data.ConfigStore, items []Item, query string) ([]Result, error) {
+	model := configStore.GetEmbedModel()
+	cache := loadCache(model)
+
+	// Embed only the items that aren't cached yet, in a single request.
+	var missing []string
+	var missingKeys []string
+	keys := make([]string, len(items))
+	for i, it := range items {
+		keys[i] = hashText(it.Text())
+		if _, ok := cache.Vectors[keys[i]]; !ok {
+			missing = append(missing, it.Text())
+			missingKeys = append(missingKeys, keys[i])
+		}
+	}
+
+	vectors, err := ai.Embed(ctx, configStore, append(missing, query))
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range missingKeys {
+		cache.Vectors[key] = vectors[i]
+	}
+	queryVector := vectors[len(vectors)-1]
+
+	cache.prune(keys)
+	cache.save()
+
+	results := make([]Result, len(items))
+	for i, it := range items {
+		results[i] = Result{Item: it, Score: cosine(queryVector, cache.Vectors[keys[i]])}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results, nil
+}
+
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
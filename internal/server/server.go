@@ -0,0 +1,680 @@
+// Package server exposes cam's stacks as a local JSON API, so editor plugins
+// and launchers can read and change them without parsing cam's output.
+//
+// Every endpoint except GET /v1/health needs "Authorization: Bearer <token>".
+// Stack names containing "/" (source stacks such as "team/deploy") are
+// written with %2F in paths. Commands are addressed by their index in the
+// stack, as in the CLI.
+//
+//	GET    /v1/health
+//	GET    /v1/stacks
+//	GET    /v1/stacks/{stack}
+//	PUT    /v1/stacks/{stack}                    {"commands": [command, ...]}
+//	DELETE /v1/stacks/{stack}
+//	POST   /v1/stacks/{stack}/commands           command, added at index 0
+//	GET    /v1/stacks/{stack}/commands/{index}
+//	PATCH  /v1/stacks/{stack}/commands/{index}   fields to change
+//	DELETE /v1/stacks/{stack}/commands/{index}
+//	GET    /v1/search?q=...&mode=fuzzy|semantic&limit=10
+//	POST   /v1/render                            {"stack", "index"} or {"cmd"}, "values"
+//
+// A command is {"cmd", "description", "tags", "private"}; responses add
+// "index", "timestamp" and the "params" (<name> and <name=default>
+// placeholders) of the command. Errors are {"error": "..."} with a 4xx or
+// 5xx status.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cam/internal/data"
+	"cam/internal/interop"
+	"cam/internal/safety"
+	"cam/internal/search"
+)
+
+// maxBody limits request bodies.
+const maxBody = 1 << 20
+
+// Options configure a Server.
+type Options struct {
+	Token string
+	// Private includes the text of private commands in responses. Without
+	// it they are listed (to keep indexes stable) with an empty "cmd".
+	Private bool
+}
+
+// Server handles API requests. Each request works on a freshly loaded
+// DataStore, so changes made with the CLI in the meantime are picked up.
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+	// mu lets reads run in parallel while writes, which also take the data
+	// file lock through data.DataStore.Update, run one at a time.
+	mu sync.RWMutex
+	// searchMu serialises semantic searches, which update the embedding cache.
+	searchMu sync.Mutex
+}
+
+// New returns a Server requiring opts.Token.
+func New(opts Options) *Server {
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /v1/health", s.health)
+	s.mux.HandleFunc("GET /v1/stacks", s.handle(s.listStacks))
+	s.mux.HandleFunc("GET /v1/stacks/{stack}", s.handle(s.getStack))
+	s.mux.HandleFunc("PUT /v1/stacks/{stack}", s.handle(s.putStack))
+	s.mux.HandleFunc("DELETE /v1/stacks/{stack}", s.handle(s.deleteStack))
+	s.mux.HandleFunc("POST /v1/stacks/{stack}/commands", s.handle(s.addCommand))
+	s.mux.HandleFunc("GET /v1/stacks/{stack}/commands/{index}", s.handle(s.getCommand))
+	s.mux.HandleFunc("PATCH /v1/stacks/{stack}/commands/{index}", s.handle(s.patchCommand))
+	s.mux.HandleFunc("DELETE /v1/stacks/{stack}/commands/{index}", s.handle(s.deleteCommand))
+	s.mux.HandleFunc("GET /v1/search", s.handle(s.search))
+	s.mux.HandleFunc("POST /v1/render", s.handle(s.render))
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, &apiError{http.StatusNotFound, "no such endpoint: " + r.Method + " " + r.URL.Path})
+	})
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/health" && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, &apiError{http.StatusUnauthorized, "missing or invalid token"})
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.opts.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
+
+// apiError is an error with the HTTP status to report it with.
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e *apiError) Error() string { return e.msg }
+
+func badRequest(format string, args ...any) error {
+	return &apiError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...any) error {
+	return &apiError{http.StatusNotFound, fmt.Sprintf(format, args...)}
+}
+
+// handle adapts a handler returning a response value or an error.
+func (s *Server) handle(h func(r *http.Request) (int, any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, body, err := h(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if body == nil {
+			w.WriteHeader(status)
+			return
+		}
+		writeJSON(w, status, body)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(body)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		status = apiErr.status
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func readJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// load reads the stacks for a request that doesn't change them.
+func (s *Server) load() (*data.DataStore, error) {
+	store := data.NewDataStore()
+	if err := store.LoadData(true); err != nil {
+		return nil, fmt.Errorf("failed to load data: %w", err)
+	}
+	return store, nil
+}
+
+// update runs fn on freshly loaded stacks and saves them if it succeeds.
+func (s *Server) update(fn func(store *data.DataStore) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store := data.NewDataStore()
+	return store.Update(func() error { return fn(store) })
+}
+
+// Command is a command as the API returns it.
+type Command struct {
+	Index       int      `json:"index"`
+	Cmd         string   `json:"cmd"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags"`
+	Private     bool     `json:"private"`
+	Timestamp   string   `json:"timestamp,omitempty"`
+	Params      []Param  `json:"params,omitempty"`
+}
+
+type Param struct {
+	Name    string `json:"name"`
+	Default string `json:"default,omitempty"`
+}
+
+// CommandInput is a command sent by a client. Fields left out are unchanged
+// when patching.
+type CommandInput struct {
+	Cmd         *string   `json:"cmd"`
+	Description *string   `json:"description"`
+	Tags        *[]string `json:"tags"`
+	Private     *bool     `json:"private"`
+}
+
+// Stack summarises a stack in GET /v1/stacks.
+type Stack struct {
+	Name     string `json:"name"`
+	Commands int    `json:"commands"`
+	ReadOnly bool   `json:"read_only"`
+	Source   string `json:"source,omitempty"`
+}
+
+func (s *Server) command(index int, c data.Command) Command {
+	out := Command{
+		Index:       index,
+		Cmd:         c.Cmd,
+		Description: c.Description,
+		Tags:        c.Tags,
+		Private:     c.IsPrivate,
+		Timestamp:   c.Timestamp,
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	if c.IsPrivate && !s.opts.Private {
+		out.Cmd = ""
+	}
+	for _, p := range interop.Params(out.Cmd) {
+		out.Params = append(out.Params, Param{Name: p.Name, Default: p.Default})
+	}
+	return out
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) listStacks(r *http.Request) (int, any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	store, err := s.load()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	stacks := []Stack{}
+	for name, commands := range store.Stacks {
+		st := Stack{Name: name, Commands: len(commands), ReadOnly: store.IsReadOnly(name)}
+		if src, ok := store.SourceOf(name); ok {
+			st.Source = src.Name
+		}
+		stacks = append(stacks, st)
+	}
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i].Name < stacks[j].Name })
+	return http.StatusOK, map[string]any{"stacks": stacks}, nil
+}
+
+func (s *Server) getStack(r *http.Request) (int, any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	store, err := s.load()
+	if err != nil {
+		return 0, nil, err
+	}
+	name := r.PathValue("stack")
+	stack, err := existingStack(store, name)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	commands := make([]Command, len(stack))
+	for i, c := range stack {
+		commands[i] = s.command(i, c)
+	}
+	body := map[string]any{"name": name, "read_only": store.IsReadOnly(name), "commands": commands}
+	if src, ok := store.SourceOf(name); ok {
+		body["source"] = src.Name
+	}
+	return http.StatusOK, body, nil
+}
+
+func (s *Server) putStack(r *http.Request) (int, any, error) {
+	var body struct {
+		Commands []CommandInput `json:"commands"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		return 0, nil, err
+	}
+	commands := make([]data.Command, len(body.Commands))
+	for i, in := range body.Commands {
+		c, err := newCommand(in)
+		if err != nil {
+			return 0, nil, badRequest("command %d: %v", i, err)
+		}
+		commands[i] = c
+	}
+
+	name := r.PathValue("stack")
+	err := s.update(func(store *data.DataStore) error {
+		if err := writable(store, name); err != nil {
+			return err
+		}
+		if !s.opts.Private {
+			for _, c := range store.Stacks[name] {
+				if c.IsPrivate {
+					return &apiError{http.StatusConflict, fmt.Sprintf("stack '%s' has private commands; replacing it needs the server started with --private", name)}
+				}
+			}
+		}
+		if len(commands) == 0 {
+			delete(store.Stacks, name)
+			return nil
+		}
+		return store.SetStack(name, commands)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) deleteStack(r *http.Request) (int, any, error) {
+	name := r.PathValue("stack")
+	err := s.update(func(store *data.DataStore) error {
+		if _, err := existingStack(store, name); err != nil {
+			return err
+		}
+		if err := writable(store, name); err != nil {
+			return err
+		}
+		return store.RemoveStack(name)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) addCommand(r *http.Request) (int, any, error) {
+	var in CommandInput
+	if err := readJSON(r, &in); err != nil {
+		return 0, nil, err
+	}
+	c, err := newCommand(in)
+	if err != nil {
+		return 0, nil, badRequest("%v", err)
+	}
+
+	name := r.PathValue("stack")
+	var added data.Command
+	err = s.update(func(store *data.DataStore) error {
+		if err := writable(store, name); err != nil {
+			return err
+		}
+		if err := store.AddCommand(name, c.Cmd, c.Description, c.Tags, c.IsPrivate); err != nil {
+			return err
+		}
+		added = store.Stacks[name][0]
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, s.command(0, added), nil
+}
+
+func (s *Server) getCommand(r *http.Request) (int, any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	store, err := s.load()
+	if err != nil {
+		return 0, nil, err
+	}
+	c, index, err := existingCommand(store, r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, s.command(index, c), nil
+}
+
+func (s *Server) patchCommand(r *http.Request) (int, any, error) {
+	var in CommandInput
+	if err := readJSON(r, &in); err != nil {
+		return 0, nil, err
+	}
+	if in.Cmd != nil && strings.TrimSpace(*in.Cmd) == "" {
+		return 0, nil, badRequest("cmd can't be empty")
+	}
+
+	name := r.PathValue("stack")
+	var patched data.Command
+	var index int
+	err := s.update(func(store *data.DataStore) error {
+		c, i, err := existingCommand(store, r)
+		if err != nil {
+			return err
+		}
+		if err := writable(store, name); err != nil {
+			return err
+		}
+		if c.IsPrivate && !s.opts.Private {
+			// Without the text, a client could make a private command public or overwrite it blindly.
+			return &apiError{http.StatusForbidden, "changing private commands needs the server started with --private"}
+		}
+
+		if in.Cmd != nil {
+			c.Cmd = *in.Cmd
+		}
+		if in.Description != nil {
+			c.Description = *in.Description
+		}
+		if in.Tags != nil {
+			c.Tags = normalizeTags(*in.Tags)
+		}
+		if in.Private != nil && *in.Private != c.IsPrivate {
+			c.IsPrivate = *in.Private
+			// The stored ciphertext no longer applies either way.
+			c.Encrypted = ""
+		}
+
+		stack := store.GetStack(name)
+		stack[i] = c
+		patched, index = c, i
+		return store.SetStack(name, stack)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, s.command(index, patched), nil
+}
+
+func (s *Server) deleteCommand(r *http.Request) (int, any, error) {
+	name := r.PathValue("stack")
+	err := s.update(func(store *data.DataStore) error {
+		_, index, err := existingCommand(store, r)
+		if err != nil {
+			return err
+		}
+		if err := writable(store, name); err != nil {
+			return err
+		}
+		if err := store.RemoveCommand(name, index); err != nil {
+			return err
+		}
+		if len(store.Stacks[name]) == 0 {
+			return store.RemoveStack(name)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// SearchResult is a command found by GET /v1/search.
+type SearchResult struct {
+	Stack string  `json:"stack"`
+	Score float64 `json:"score"`
+	Command
+}
+
+func (s *Server) search(r *http.Request) (int, any, error) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		return 0, nil, badRequest("missing query parameter q")
+	}
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = "fuzzy"
+	} else if mode != "fuzzy" && mode != "semantic" {
+		return 0, nil, badRequest("invalid mode '%s' (use fuzzy or semantic)", mode)
+	}
+	limit := 10
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			return 0, nil, badRequest("invalid limit '%s'", l)
+		}
+		limit = n
+	}
+
+	s.mu.RLock()
+	store, err := s.load()
+	s.mu.RUnlock()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Searching hidden private commands would leak them through the ranking,
+	// and embedding them would store them in the embedding cache, so they are
+	// only ever fuzzy matched.
+	var items, privateItems []search.Item
+	for _, it := range search.Items(store.Stacks) {
+		switch {
+		case !it.Command.IsPrivate:
+			items = append(items, it)
+		case s.opts.Private:
+			privateItems = append(privateItems, it)
+		}
+	}
+
+	var results []search.Result
+	var warning string
+	if mode == "semantic" && len(items) > 0 {
+		configStore := data.NewConfigStore()
+		if err := configStore.LoadConfig(); err != nil {
+			return 0, nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		s.searchMu.Lock()
+		results, err = search.Semantic(r.Context(), configStore, items, query)
+		s.searchMu.Unlock()
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return 0, nil, err
+			}
+			warning = fmt.Sprintf("semantic search unavailable (%v); fell back to fuzzy matching", err)
+			mode = "fuzzy"
+		}
+	}
+	if mode == "fuzzy" {
+		results = search.Fuzzy(append(items, privateItems...), query)
+	} else {
+		// Fuzzy scores aren't comparable to similarities; list private matches after the rest.
+		for _, res := range search.Fuzzy(privateItems, query) {
+			res.Score = 0
+			results = append(results, res)
+		}
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	out := []SearchResult{}
+	for _, res := range results {
+		out = append(out, SearchResult{Stack: res.Stack, Score: res.Score, Command: s.command(res.Index, res.Command)})
+	}
+	body := map[string]any{"mode": mode, "results": out}
+	if warning != "" {
+		body["warning"] = warning
+	}
+	return http.StatusOK, body, nil
+}
+
+func (s *Server) render(r *http.Request) (int, any, error) {
+	var in struct {
+		Stack  string            `json:"stack"`
+		Index  *int              `json:"index"`
+		Cmd    string            `json:"cmd"`
+		Values map[string]string `json:"values"`
+	}
+	if err := readJSON(r, &in); err != nil {
+		return 0, nil, err
+	}
+
+	template := in.Cmd
+	switch {
+	case in.Stack != "" && in.Cmd != "":
+		return 0, nil, badRequest("give either stack and index or cmd, not both")
+	case in.Stack != "":
+		s.mu.RLock()
+		store, err := s.load()
+		s.mu.RUnlock()
+		if err != nil {
+			return 0, nil, err
+		}
+		stack, err := existingStack(store, in.Stack)
+		if err != nil {
+			return 0, nil, err
+		}
+		index := 0
+		if in.Index != nil {
+			index = *in.Index
+		}
+		if index < 0 || index >= len(stack) {
+			return 0, nil, notFound("index %d is out of bounds for stack '%s' (length %d)", index, in.Stack, len(stack))
+		}
+		if stack[index].IsPrivate && !s.opts.Private {
+			return 0, nil, &apiError{http.StatusForbidden, "rendering private commands needs the server started with --private"}
+		}
+		template = stack[index].Cmd
+	case strings.TrimSpace(in.Cmd) == "":
+		return 0, nil, badRequest("give stack and index, or cmd")
+	}
+
+	missing := []string{}
+	rendered := interop.ReplaceParams(template, func(p interop.Param) string {
+		if v, ok := in.Values[p.Name]; ok {
+			return v
+		}
+		if p.Default != "" {
+			return p.Default
+		}
+		if !slices.Contains(missing, p.Name) {
+			missing = append(missing, p.Name)
+		}
+		return "<" + p.Name + ">"
+	})
+
+	// Unfilled placeholders would read as redirections, so check the command
+	// with their names in place.
+	analysed := interop.ReplaceParams(rendered, func(p interop.Param) string { return p.Name })
+	risks := []string{}
+	for _, risk := range safety.Analyze(analysed) {
+		risks = append(risks, risk.Reason)
+	}
+
+	params := []Param{}
+	for _, p := range interop.Params(template) {
+		params = append(params, Param{Name: p.Name, Default: p.Default})
+	}
+	return http.StatusOK, map[string]any{
+		"cmd":     rendered,
+		"params":  params,
+		"missing": missing,
+		"risks":   risks,
+	}, nil
+}
+
+// newCommand validates a command sent for creation.
+func newCommand(in CommandInput) (data.Command, error) {
+	if in.Cmd == nil || strings.TrimSpace(*in.Cmd) == "" {
+		return data.Command{}, fmt.Errorf("cmd is required")
+	}
+	c := data.Command{Cmd: *in.Cmd, Tags: []string{}, Timestamp: time.Now().Format(time.RFC3339)}
+	if in.Description != nil {
+		c.Description = *in.Description
+	}
+	if in.Tags != nil {
+		c.Tags = normalizeTags(*in.Tags)
+	}
+	if in.Private != nil {
+		c.IsPrivate = *in.Private
+	}
+	return c, nil
+}
+
+func normalizeTags(tags []string) []string {
+	out := []string{}
+	for _, t := range tags {
+		if t = strings.TrimSpace(t); t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func existingStack(store *data.DataStore, name string) ([]data.Command, error) {
+	stack := store.GetStack(name)
+	if len(stack) == 0 {
+		return nil, notFound("stack '%s' does not exist", name)
+	}
+	return stack, nil
+}
+
+func existingCommand(store *data.DataStore, r *http.Request) (data.Command, int, error) {
+	name := r.PathValue("stack")
+	stack, err := existingStack(store, name)
+	if err != nil {
+		return data.Command{}, 0, err
+	}
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		return data.Command{}, 0, badRequest("invalid index '%s'", r.PathValue("index"))
+	}
+	if index < 0 || index >= len(stack) {
+		return data.Command{}, 0, notFound("index %d is out of bounds for stack '%s' (length %d)", index, name, len(stack))
+	}
+	return stack[index], index, nil
+}
+
+func writable(store *data.DataStore, name string) error {
+	if store.IsReadOnly(name) {
+		return &apiError{http.StatusForbidden, fmt.Sprintf("stack '%s' is read-only", name)}
+	}
+	if strings.TrimSpace(name) == "" {
+		return badRequest("stack name can't be empty")
+	}
+	return nil
+}
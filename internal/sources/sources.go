@@ -0,0 +1,170 @@
+// Package sources fetches the stacks of subscribed stack sources (see
+// data.StackSource) into local snapshots.
+package sources
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cam/internal/bundle"
+	"cam/internal/data"
+)
+
+// BundleFile is a bundle published at the root of a directory or git
+// source. When present it is read instead of the project layout, so the
+// stacks can be signed.
+const BundleFile = "cam-bundle.json"
+
+// DetectKind guesses the kind of a source from its location: a file is a
+// bundle, a bare repository or anything that isn't a local path is cloned
+// with git, and other directories are read in place.
+func DetectKind(location string) string {
+	info, err := os.Stat(location)
+	switch {
+	case err != nil:
+		return data.SourceGit
+	case !info.IsDir():
+		return data.SourceBundle
+	case isBareRepo(location):
+		return data.SourceGit
+	}
+	return data.SourceDir
+}
+
+// Location normalises a location so it works from any directory.
+func Location(location string) string {
+	if _, err := os.Stat(location); err == nil {
+		if abs, err := filepath.Abs(location); err == nil {
+			return abs
+		}
+	}
+	return location
+}
+
+// Update fetches a source, stores its snapshot and returns the number of
+// stacks it provides. src.Updated and src.Signer are set on success; a
+// bundle with an invalid signature fails the update.
+func Update(src *data.StackSource) (int, error) {
+	var stacks map[string][]data.Command
+	var signer string
+	var err error
+	switch src.Kind {
+	case data.SourceDir:
+		stacks, signer, err = readDir(src.Location)
+	case data.SourceGit:
+		stacks, signer, err = fetchGit(src)
+	case data.SourceBundle:
+		stacks, signer, err = readBundle(src.Location)
+	default:
+		err = fmt.Errorf("unknown kind '%s'", src.Kind)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to update source '%s': %w", src.Name, err)
+	}
+	if len(stacks) == 0 {
+		return 0, fmt.Errorf("source '%s' has no stacks (expected %s or %s/ in %s)", src.Name, data.ProjectFile, data.ProjectDir, src.Location)
+	}
+
+	if err := data.WriteSourceSnapshot(src.Name, stacks); err != nil {
+		return 0, err
+	}
+	src.Updated = time.Now().Format(time.RFC3339)
+	src.Signer = signer
+	return len(stacks), nil
+}
+
+// readDir reads the directory's bundle if it publishes one, and its
+// project stacks otherwise.
+func readDir(dir string) (map[string][]data.Command, string, error) {
+	path := filepath.Join(dir, BundleFile)
+	if _, err := os.Stat(path); err == nil {
+		return readBundle(path)
+	}
+	stacks, err := (&data.Project{Root: dir}).Stacks()
+	return stacks, "", err
+}
+
+// fetchGit clones the repository on first use and pulls it afterwards.
+func fetchGit(src *data.StackSource) (map[string][]data.Command, string, error) {
+	cacheDir, err := data.SourceCacheDir()
+	if err != nil {
+		return nil, "", err
+	}
+	clone := filepath.Join(cacheDir, src.Name)
+
+	if _, err := os.Stat(filepath.Join(clone, ".git")); err == nil {
+		if err := git(clone, "remote", "set-url", "origin", src.Location); err != nil {
+			return nil, "", err
+		}
+		if err := git(clone, "pull", "-q", "--ff-only"); err != nil {
+			return nil, "", err
+		}
+	} else {
+		os.RemoveAll(clone)
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return nil, "", err
+		}
+		if err := git(cacheDir, "clone", "-q", "--depth", "1", src.Location, clone); err != nil {
+			return nil, "", err
+		}
+	}
+	return readDir(clone)
+}
+
+// readBundle reads a bundle's public commands and returns them with the
+// key that signed it, if any.
+func readBundle(path string) (map[string][]data.Command, string, error) {
+	b, err := bundle.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	signer, err := b.Verify()
+	if err != nil && !errors.Is(err, bundle.ErrUnsigned) {
+		return nil, "", err
+	}
+
+	stacks := make(map[string][]data.Command)
+	for _, s := range b.Stacks {
+		// Sources are shared, so private commands are never taken over.
+		commands, err := s.DataCommands("")
+		if err != nil {
+			return nil, "", err
+		}
+		if len(commands) > 0 {
+			stacks[s.Name] = commands
+		}
+	}
+	return stacks, signer, nil
+}
+
+func isBareRepo(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func git(dir string, args ...string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git sources need git installed: %w", err)
+	}
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return nil
+}
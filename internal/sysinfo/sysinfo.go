@@ -0,0 +1,153 @@
+package sysinfo
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Info describes the environment generated commands will run in.
+type Info struct {
+	Shell     string   // e.g. "zsh"
+	OS        string   // runtime.GOOS, e.g. "linux"
+	Distro    string   // e.g. "Ubuntu 24.04 LTS" or "macOS 14.5"
+	Coreutils string   // "GNU", "BSD" or "BusyBox"
+	Tools     []string // notable tools found on PATH
+}
+
+var knownShells = map[string]bool{
+	"sh":   true,
+	"bash": true,
+	"zsh":  true,
+	"fish": true,
+	"dash": true,
+	"ksh":  true,
+	"nu":   true,
+	"pwsh": true,
+}
+
+// notableTools are worth telling the model about because they change the best answer.
+var notableTools = []string{
+	"fd", "rg", "fzf", "jq", "yq", "bat", "eza", "gsed", "gawk",
+	"git", "docker", "podman", "kubectl", "python3", "node", "go",
+	"curl", "wget", "brew", "apt", "dnf", "pacman",
+}
+
+// Detect inspects the current environment. The result is computed once per
+// process, since probing runs ls --version and looks up every known tool.
+func Detect() Info {
+	info := detected()
+	info.Tools = slices.Clone(info.Tools)
+	return info
+}
+
+var detected = sync.OnceValue(func() Info {
+	return Info{
+		Shell:     DetectShell(),
+		OS:        runtime.GOOS,
+		Distro:    detectDistro(),
+		Coreutils: detectCoreutils(),
+		Tools:     detectTools(),
+	}
+})
+
+// DetectShell prefers the shell cam was launched from, falling back to $SHELL.
+func DetectShell() string {
+	if parent := parentProcessName(); knownShells[parent] {
+		return parent
+	}
+	if shell := filepath.Base(os.Getenv("SHELL")); knownShells[shell] {
+		return shell
+	}
+	return "sh"
+}
+
+func parentProcessName() string {
+	ppid := os.Getppid()
+
+	// Linux exposes the name directly; elsewhere ask ps.
+	if content, err := os.ReadFile("/proc/" + strconv.Itoa(ppid) + "/comm"); err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(string(content)), "-")
+	}
+	out, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(ppid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(filepath.Base(strings.TrimSpace(string(out))), "-")
+}
+
+func detectDistro() string {
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := exec.Command("sw_vers", "-productVersion").Output()
+		if err != nil {
+			return "macOS"
+		}
+		return "macOS " + strings.TrimSpace(string(out))
+	case "linux":
+		f, err := os.Open("/etc/os-release")
+		if err != nil {
+			return ""
+		}
+		defer f.Close()
+
+		fields := make(map[string]string)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+				fields[key] = strings.Trim(value, `"'`)
+			}
+		}
+		if fields["PRETTY_NAME"] != "" {
+			return fields["PRETTY_NAME"]
+		}
+		return strings.TrimSpace(fields["NAME"] + " " + fields["VERSION_ID"])
+	}
+	return ""
+}
+
+func detectCoreutils() string {
+	out, err := exec.Command("ls", "--version").CombinedOutput()
+	switch {
+	case err == nil && strings.Contains(string(out), "GNU"):
+		return "GNU"
+	case strings.Contains(string(out), "BusyBox"):
+		return "BusyBox"
+	case runtime.GOOS == "darwin" || strings.HasSuffix(runtime.GOOS, "bsd"):
+		return "BSD"
+	}
+	return ""
+}
+
+func detectTools() []string {
+	var found []string
+	for _, tool := range notableTools {
+		if _, err := exec.LookPath(tool); err == nil {
+			found = append(found, tool)
+		}
+	}
+	return found
+}
+
+// Missing returns the executables in names that can't be found on PATH.
+// Names containing a slash are checked as paths.
+func Missing(names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if strings.Contains(name, "/") {
+			if info, err := os.Stat(name); err == nil && !info.IsDir() {
+				continue
+			}
+		} else if _, err := exec.LookPath(name); err == nil {
+			continue
+		}
+		missing = append(missing, name)
+	}
+	return missing
+}